@@ -0,0 +1,1105 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.12.4
+// source: gophkeeper/gophkeeper.proto
+
+package gophkeeperv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// GophKeeperClient is the client API for GophKeeper service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GophKeeperClient interface {
+	SignIn(ctx context.Context, in *SingInRequest, opts ...grpc.CallOption) (*SignInResponse, error)
+	SignUp(ctx context.Context, in *SignUpRequest, opts ...grpc.CallOption) (*SignUpResponse, error)
+	SrpRegister(ctx context.Context, in *SrpRegisterRequest, opts ...grpc.CallOption) (*SrpRegisterResponse, error)
+	SrpStart(ctx context.Context, in *SrpStartRequest, opts ...grpc.CallOption) (*SrpStartResponse, error)
+	SrpVerify(ctx context.Context, in *SrpVerifyRequest, opts ...grpc.CallOption) (*SrpVerifyResponse, error)
+	SyncDB(ctx context.Context, in *SyncDBRequest, opts ...grpc.CallOption) (*SyncDBResponse, error)
+	ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*ChangePasswordResponse, error)
+	ChangeLogin(ctx context.Context, in *ChangeLoginRequest, opts ...grpc.CallOption) (*ChangeLoginResponse, error)
+	DeleteAccount(ctx context.Context, in *DeleteAccountRequest, opts ...grpc.CallOption) (*DeleteAccountResponse, error)
+	ListItems(ctx context.Context, in *ListItemsRequest, opts ...grpc.CallOption) (*ListItemsResponse, error)
+	SearchItems(ctx context.Context, in *SearchItemsRequest, opts ...grpc.CallOption) (*ListItemsResponse, error)
+	GetItem(ctx context.Context, in *GetItemRequest, opts ...grpc.CallOption) (*GetItemResponse, error)
+	ExportVault(ctx context.Context, in *ExportVaultRequest, opts ...grpc.CallOption) (*ExportVaultResponse, error)
+	ImportVault(ctx context.Context, in *ImportVaultRequest, opts ...grpc.CallOption) (*ImportVaultResponse, error)
+	ImportExternal(ctx context.Context, in *ImportExternalRequest, opts ...grpc.CallOption) (*ImportExternalResponse, error)
+	WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (GophKeeper_WatchChangesClient, error)
+	GetKeyBundles(ctx context.Context, in *GetKeyBundlesRequest, opts ...grpc.CallOption) (*GetKeyBundlesResponse, error)
+	PutKeyBundle(ctx context.Context, in *PutKeyBundleRequest, opts ...grpc.CallOption) (*PutKeyBundleResponse, error)
+	DeleteKeyBundle(ctx context.Context, in *DeleteKeyBundleRequest, opts ...grpc.CallOption) (*DeleteKeyBundleResponse, error)
+	RegenerateRecoveryCodes(ctx context.Context, in *RegenerateRecoveryCodesRequest, opts ...grpc.CallOption) (*RegenerateRecoveryCodesResponse, error)
+	RecoverAccount(ctx context.Context, in *RecoverAccountRequest, opts ...grpc.CallOption) (*RecoverAccountResponse, error)
+	AddEmergencyContact(ctx context.Context, in *AddEmergencyContactRequest, opts ...grpc.CallOption) (*AddEmergencyContactResponse, error)
+	RemoveEmergencyContact(ctx context.Context, in *RemoveEmergencyContactRequest, opts ...grpc.CallOption) (*RemoveEmergencyContactResponse, error)
+	ListEmergencyContacts(ctx context.Context, in *ListEmergencyContactsRequest, opts ...grpc.CallOption) (*ListEmergencyContactsResponse, error)
+	RequestEmergencyAccess(ctx context.Context, in *RequestEmergencyAccessRequest, opts ...grpc.CallOption) (*RequestEmergencyAccessResponse, error)
+	DenyEmergencyAccess(ctx context.Context, in *DenyEmergencyAccessRequest, opts ...grpc.CallOption) (*DenyEmergencyAccessResponse, error)
+	GetEmergencyVault(ctx context.Context, in *GetEmergencyVaultRequest, opts ...grpc.CallOption) (*GetEmergencyVaultResponse, error)
+	ListAccountEvents(ctx context.Context, in *ListAccountEventsRequest, opts ...grpc.CallOption) (*ListAccountEventsResponse, error)
+}
+
+type gophKeeperClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGophKeeperClient(cc grpc.ClientConnInterface) GophKeeperClient {
+	return &gophKeeperClient{cc}
+}
+
+func (c *gophKeeperClient) SignIn(ctx context.Context, in *SingInRequest, opts ...grpc.CallOption) (*SignInResponse, error) {
+	out := new(SignInResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/SignIn", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) SignUp(ctx context.Context, in *SignUpRequest, opts ...grpc.CallOption) (*SignUpResponse, error) {
+	out := new(SignUpResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/SignUp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) SrpRegister(ctx context.Context, in *SrpRegisterRequest, opts ...grpc.CallOption) (*SrpRegisterResponse, error) {
+	out := new(SrpRegisterResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/SrpRegister", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) SrpStart(ctx context.Context, in *SrpStartRequest, opts ...grpc.CallOption) (*SrpStartResponse, error) {
+	out := new(SrpStartResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/SrpStart", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) SrpVerify(ctx context.Context, in *SrpVerifyRequest, opts ...grpc.CallOption) (*SrpVerifyResponse, error) {
+	out := new(SrpVerifyResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/SrpVerify", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) SyncDB(ctx context.Context, in *SyncDBRequest, opts ...grpc.CallOption) (*SyncDBResponse, error) {
+	out := new(SyncDBResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/SyncDB", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*ChangePasswordResponse, error) {
+	out := new(ChangePasswordResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/ChangePassword", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ChangeLogin(ctx context.Context, in *ChangeLoginRequest, opts ...grpc.CallOption) (*ChangeLoginResponse, error) {
+	out := new(ChangeLoginResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/ChangeLogin", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) DeleteAccount(ctx context.Context, in *DeleteAccountRequest, opts ...grpc.CallOption) (*DeleteAccountResponse, error) {
+	out := new(DeleteAccountResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/DeleteAccount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ListItems(ctx context.Context, in *ListItemsRequest, opts ...grpc.CallOption) (*ListItemsResponse, error) {
+	out := new(ListItemsResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/ListItems", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) SearchItems(ctx context.Context, in *SearchItemsRequest, opts ...grpc.CallOption) (*ListItemsResponse, error) {
+	out := new(ListItemsResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/SearchItems", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) GetItem(ctx context.Context, in *GetItemRequest, opts ...grpc.CallOption) (*GetItemResponse, error) {
+	out := new(GetItemResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/GetItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ExportVault(ctx context.Context, in *ExportVaultRequest, opts ...grpc.CallOption) (*ExportVaultResponse, error) {
+	out := new(ExportVaultResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/ExportVault", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ImportVault(ctx context.Context, in *ImportVaultRequest, opts ...grpc.CallOption) (*ImportVaultResponse, error) {
+	out := new(ImportVaultResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/ImportVault", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ImportExternal(ctx context.Context, in *ImportExternalRequest, opts ...grpc.CallOption) (*ImportExternalResponse, error) {
+	out := new(ImportExternalResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/ImportExternal", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (GophKeeper_WatchChangesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GophKeeper_ServiceDesc.Streams[0], "/gophkeeper.GophKeeper/WatchChanges", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gophKeeperWatchChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GophKeeper_WatchChangesClient interface {
+	Recv() (*WatchChangesResponse, error)
+	grpc.ClientStream
+}
+
+type gophKeeperWatchChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *gophKeeperWatchChangesClient) Recv() (*WatchChangesResponse, error) {
+	m := new(WatchChangesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *gophKeeperClient) GetKeyBundles(ctx context.Context, in *GetKeyBundlesRequest, opts ...grpc.CallOption) (*GetKeyBundlesResponse, error) {
+	out := new(GetKeyBundlesResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/GetKeyBundles", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) PutKeyBundle(ctx context.Context, in *PutKeyBundleRequest, opts ...grpc.CallOption) (*PutKeyBundleResponse, error) {
+	out := new(PutKeyBundleResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/PutKeyBundle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) DeleteKeyBundle(ctx context.Context, in *DeleteKeyBundleRequest, opts ...grpc.CallOption) (*DeleteKeyBundleResponse, error) {
+	out := new(DeleteKeyBundleResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/DeleteKeyBundle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) RegenerateRecoveryCodes(ctx context.Context, in *RegenerateRecoveryCodesRequest, opts ...grpc.CallOption) (*RegenerateRecoveryCodesResponse, error) {
+	out := new(RegenerateRecoveryCodesResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/RegenerateRecoveryCodes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) RecoverAccount(ctx context.Context, in *RecoverAccountRequest, opts ...grpc.CallOption) (*RecoverAccountResponse, error) {
+	out := new(RecoverAccountResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/RecoverAccount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) AddEmergencyContact(ctx context.Context, in *AddEmergencyContactRequest, opts ...grpc.CallOption) (*AddEmergencyContactResponse, error) {
+	out := new(AddEmergencyContactResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/AddEmergencyContact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) RemoveEmergencyContact(ctx context.Context, in *RemoveEmergencyContactRequest, opts ...grpc.CallOption) (*RemoveEmergencyContactResponse, error) {
+	out := new(RemoveEmergencyContactResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/RemoveEmergencyContact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ListEmergencyContacts(ctx context.Context, in *ListEmergencyContactsRequest, opts ...grpc.CallOption) (*ListEmergencyContactsResponse, error) {
+	out := new(ListEmergencyContactsResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/ListEmergencyContacts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) RequestEmergencyAccess(ctx context.Context, in *RequestEmergencyAccessRequest, opts ...grpc.CallOption) (*RequestEmergencyAccessResponse, error) {
+	out := new(RequestEmergencyAccessResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/RequestEmergencyAccess", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) DenyEmergencyAccess(ctx context.Context, in *DenyEmergencyAccessRequest, opts ...grpc.CallOption) (*DenyEmergencyAccessResponse, error) {
+	out := new(DenyEmergencyAccessResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/DenyEmergencyAccess", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) GetEmergencyVault(ctx context.Context, in *GetEmergencyVaultRequest, opts ...grpc.CallOption) (*GetEmergencyVaultResponse, error) {
+	out := new(GetEmergencyVaultResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/GetEmergencyVault", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ListAccountEvents(ctx context.Context, in *ListAccountEventsRequest, opts ...grpc.CallOption) (*ListAccountEventsResponse, error) {
+	out := new(ListAccountEventsResponse)
+	err := c.cc.Invoke(ctx, "/gophkeeper.GophKeeper/ListAccountEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GophKeeperServer is the server API for GophKeeper service.
+// All implementations must embed UnimplementedGophKeeperServer
+// for forward compatibility
+type GophKeeperServer interface {
+	SignIn(context.Context, *SingInRequest) (*SignInResponse, error)
+	SignUp(context.Context, *SignUpRequest) (*SignUpResponse, error)
+	SrpRegister(context.Context, *SrpRegisterRequest) (*SrpRegisterResponse, error)
+	SrpStart(context.Context, *SrpStartRequest) (*SrpStartResponse, error)
+	SrpVerify(context.Context, *SrpVerifyRequest) (*SrpVerifyResponse, error)
+	SyncDB(context.Context, *SyncDBRequest) (*SyncDBResponse, error)
+	ChangePassword(context.Context, *ChangePasswordRequest) (*ChangePasswordResponse, error)
+	ChangeLogin(context.Context, *ChangeLoginRequest) (*ChangeLoginResponse, error)
+	DeleteAccount(context.Context, *DeleteAccountRequest) (*DeleteAccountResponse, error)
+	ListItems(context.Context, *ListItemsRequest) (*ListItemsResponse, error)
+	SearchItems(context.Context, *SearchItemsRequest) (*ListItemsResponse, error)
+	GetItem(context.Context, *GetItemRequest) (*GetItemResponse, error)
+	ExportVault(context.Context, *ExportVaultRequest) (*ExportVaultResponse, error)
+	ImportVault(context.Context, *ImportVaultRequest) (*ImportVaultResponse, error)
+	ImportExternal(context.Context, *ImportExternalRequest) (*ImportExternalResponse, error)
+	WatchChanges(*WatchChangesRequest, GophKeeper_WatchChangesServer) error
+	GetKeyBundles(context.Context, *GetKeyBundlesRequest) (*GetKeyBundlesResponse, error)
+	PutKeyBundle(context.Context, *PutKeyBundleRequest) (*PutKeyBundleResponse, error)
+	DeleteKeyBundle(context.Context, *DeleteKeyBundleRequest) (*DeleteKeyBundleResponse, error)
+	RegenerateRecoveryCodes(context.Context, *RegenerateRecoveryCodesRequest) (*RegenerateRecoveryCodesResponse, error)
+	RecoverAccount(context.Context, *RecoverAccountRequest) (*RecoverAccountResponse, error)
+	AddEmergencyContact(context.Context, *AddEmergencyContactRequest) (*AddEmergencyContactResponse, error)
+	RemoveEmergencyContact(context.Context, *RemoveEmergencyContactRequest) (*RemoveEmergencyContactResponse, error)
+	ListEmergencyContacts(context.Context, *ListEmergencyContactsRequest) (*ListEmergencyContactsResponse, error)
+	RequestEmergencyAccess(context.Context, *RequestEmergencyAccessRequest) (*RequestEmergencyAccessResponse, error)
+	DenyEmergencyAccess(context.Context, *DenyEmergencyAccessRequest) (*DenyEmergencyAccessResponse, error)
+	GetEmergencyVault(context.Context, *GetEmergencyVaultRequest) (*GetEmergencyVaultResponse, error)
+	ListAccountEvents(context.Context, *ListAccountEventsRequest) (*ListAccountEventsResponse, error)
+	mustEmbedUnimplementedGophKeeperServer()
+}
+
+// UnimplementedGophKeeperServer must be embedded to have forward compatible implementations.
+type UnimplementedGophKeeperServer struct {
+}
+
+func (UnimplementedGophKeeperServer) SignIn(context.Context, *SingInRequest) (*SignInResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignIn not implemented")
+}
+func (UnimplementedGophKeeperServer) SignUp(context.Context, *SignUpRequest) (*SignUpResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignUp not implemented")
+}
+func (UnimplementedGophKeeperServer) SrpRegister(context.Context, *SrpRegisterRequest) (*SrpRegisterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SrpRegister not implemented")
+}
+func (UnimplementedGophKeeperServer) SrpStart(context.Context, *SrpStartRequest) (*SrpStartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SrpStart not implemented")
+}
+func (UnimplementedGophKeeperServer) SrpVerify(context.Context, *SrpVerifyRequest) (*SrpVerifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SrpVerify not implemented")
+}
+func (UnimplementedGophKeeperServer) SyncDB(context.Context, *SyncDBRequest) (*SyncDBResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SyncDB not implemented")
+}
+func (UnimplementedGophKeeperServer) ChangePassword(context.Context, *ChangePasswordRequest) (*ChangePasswordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangePassword not implemented")
+}
+func (UnimplementedGophKeeperServer) ChangeLogin(context.Context, *ChangeLoginRequest) (*ChangeLoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeLogin not implemented")
+}
+func (UnimplementedGophKeeperServer) DeleteAccount(context.Context, *DeleteAccountRequest) (*DeleteAccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAccount not implemented")
+}
+func (UnimplementedGophKeeperServer) ListItems(context.Context, *ListItemsRequest) (*ListItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListItems not implemented")
+}
+func (UnimplementedGophKeeperServer) SearchItems(context.Context, *SearchItemsRequest) (*ListItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchItems not implemented")
+}
+func (UnimplementedGophKeeperServer) GetItem(context.Context, *GetItemRequest) (*GetItemResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetItem not implemented")
+}
+func (UnimplementedGophKeeperServer) ExportVault(context.Context, *ExportVaultRequest) (*ExportVaultResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportVault not implemented")
+}
+func (UnimplementedGophKeeperServer) ImportVault(context.Context, *ImportVaultRequest) (*ImportVaultResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportVault not implemented")
+}
+func (UnimplementedGophKeeperServer) ImportExternal(context.Context, *ImportExternalRequest) (*ImportExternalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportExternal not implemented")
+}
+func (UnimplementedGophKeeperServer) WatchChanges(*WatchChangesRequest, GophKeeper_WatchChangesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchChanges not implemented")
+}
+func (UnimplementedGophKeeperServer) GetKeyBundles(context.Context, *GetKeyBundlesRequest) (*GetKeyBundlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKeyBundles not implemented")
+}
+func (UnimplementedGophKeeperServer) PutKeyBundle(context.Context, *PutKeyBundleRequest) (*PutKeyBundleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutKeyBundle not implemented")
+}
+func (UnimplementedGophKeeperServer) DeleteKeyBundle(context.Context, *DeleteKeyBundleRequest) (*DeleteKeyBundleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteKeyBundle not implemented")
+}
+func (UnimplementedGophKeeperServer) RegenerateRecoveryCodes(context.Context, *RegenerateRecoveryCodesRequest) (*RegenerateRecoveryCodesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegenerateRecoveryCodes not implemented")
+}
+func (UnimplementedGophKeeperServer) RecoverAccount(context.Context, *RecoverAccountRequest) (*RecoverAccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecoverAccount not implemented")
+}
+func (UnimplementedGophKeeperServer) AddEmergencyContact(context.Context, *AddEmergencyContactRequest) (*AddEmergencyContactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddEmergencyContact not implemented")
+}
+func (UnimplementedGophKeeperServer) RemoveEmergencyContact(context.Context, *RemoveEmergencyContactRequest) (*RemoveEmergencyContactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveEmergencyContact not implemented")
+}
+func (UnimplementedGophKeeperServer) ListEmergencyContacts(context.Context, *ListEmergencyContactsRequest) (*ListEmergencyContactsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEmergencyContacts not implemented")
+}
+func (UnimplementedGophKeeperServer) RequestEmergencyAccess(context.Context, *RequestEmergencyAccessRequest) (*RequestEmergencyAccessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestEmergencyAccess not implemented")
+}
+func (UnimplementedGophKeeperServer) DenyEmergencyAccess(context.Context, *DenyEmergencyAccessRequest) (*DenyEmergencyAccessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DenyEmergencyAccess not implemented")
+}
+func (UnimplementedGophKeeperServer) GetEmergencyVault(context.Context, *GetEmergencyVaultRequest) (*GetEmergencyVaultResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEmergencyVault not implemented")
+}
+func (UnimplementedGophKeeperServer) ListAccountEvents(context.Context, *ListAccountEventsRequest) (*ListAccountEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAccountEvents not implemented")
+}
+func (UnimplementedGophKeeperServer) mustEmbedUnimplementedGophKeeperServer() {}
+
+// UnsafeGophKeeperServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GophKeeperServer will
+// result in compilation errors.
+type UnsafeGophKeeperServer interface {
+	mustEmbedUnimplementedGophKeeperServer()
+}
+
+func RegisterGophKeeperServer(s grpc.ServiceRegistrar, srv GophKeeperServer) {
+	s.RegisterService(&GophKeeper_ServiceDesc, srv)
+}
+
+func _GophKeeper_SignIn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SingInRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).SignIn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/SignIn",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).SignIn(ctx, req.(*SingInRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_SignUp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignUpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).SignUp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/SignUp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).SignUp(ctx, req.(*SignUpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_SrpRegister_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SrpRegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).SrpRegister(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/SrpRegister",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).SrpRegister(ctx, req.(*SrpRegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_SrpStart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SrpStartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).SrpStart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/SrpStart",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).SrpStart(ctx, req.(*SrpStartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_SrpVerify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SrpVerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).SrpVerify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/SrpVerify",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).SrpVerify(ctx, req.(*SrpVerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_SyncDB_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncDBRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).SyncDB(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/SyncDB",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).SyncDB(ctx, req.(*SyncDBRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ChangePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ChangePassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/ChangePassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ChangePassword(ctx, req.(*ChangePasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ChangeLogin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeLoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ChangeLogin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/ChangeLogin",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ChangeLogin(ctx, req.(*ChangeLoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_DeleteAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).DeleteAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/DeleteAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).DeleteAccount(ctx, req.(*DeleteAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ListItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ListItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/ListItems",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ListItems(ctx, req.(*ListItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_SearchItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).SearchItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/SearchItems",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).SearchItems(ctx, req.(*SearchItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_GetItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).GetItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/GetItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).GetItem(ctx, req.(*GetItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ExportVault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportVaultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ExportVault(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/ExportVault",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ExportVault(ctx, req.(*ExportVaultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ImportVault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportVaultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ImportVault(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/ImportVault",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ImportVault(ctx, req.(*ImportVaultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ImportExternal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportExternalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ImportExternal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/ImportExternal",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ImportExternal(ctx, req.(*ImportExternalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_WatchChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GophKeeperServer).WatchChanges(m, &gophKeeperWatchChangesServer{stream})
+}
+
+type GophKeeper_WatchChangesServer interface {
+	Send(*WatchChangesResponse) error
+	grpc.ServerStream
+}
+
+type gophKeeperWatchChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *gophKeeperWatchChangesServer) Send(m *WatchChangesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _GophKeeper_GetKeyBundles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetKeyBundlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).GetKeyBundles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/GetKeyBundles",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).GetKeyBundles(ctx, req.(*GetKeyBundlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_PutKeyBundle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutKeyBundleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).PutKeyBundle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/PutKeyBundle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).PutKeyBundle(ctx, req.(*PutKeyBundleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_DeleteKeyBundle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteKeyBundleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).DeleteKeyBundle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/DeleteKeyBundle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).DeleteKeyBundle(ctx, req.(*DeleteKeyBundleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_RegenerateRecoveryCodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegenerateRecoveryCodesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).RegenerateRecoveryCodes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/RegenerateRecoveryCodes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).RegenerateRecoveryCodes(ctx, req.(*RegenerateRecoveryCodesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_RecoverAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecoverAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).RecoverAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/RecoverAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).RecoverAccount(ctx, req.(*RecoverAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_AddEmergencyContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddEmergencyContactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).AddEmergencyContact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/AddEmergencyContact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).AddEmergencyContact(ctx, req.(*AddEmergencyContactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_RemoveEmergencyContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveEmergencyContactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).RemoveEmergencyContact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/RemoveEmergencyContact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).RemoveEmergencyContact(ctx, req.(*RemoveEmergencyContactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ListEmergencyContacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEmergencyContactsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ListEmergencyContacts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/ListEmergencyContacts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ListEmergencyContacts(ctx, req.(*ListEmergencyContactsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_RequestEmergencyAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestEmergencyAccessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).RequestEmergencyAccess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/RequestEmergencyAccess",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).RequestEmergencyAccess(ctx, req.(*RequestEmergencyAccessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_DenyEmergencyAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DenyEmergencyAccessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).DenyEmergencyAccess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/DenyEmergencyAccess",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).DenyEmergencyAccess(ctx, req.(*DenyEmergencyAccessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_GetEmergencyVault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEmergencyVaultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).GetEmergencyVault(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/GetEmergencyVault",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).GetEmergencyVault(ctx, req.(*GetEmergencyVaultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ListAccountEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAccountEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ListAccountEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gophkeeper.GophKeeper/ListAccountEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ListAccountEvents(ctx, req.(*ListAccountEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GophKeeper_ServiceDesc is the grpc.ServiceDesc for GophKeeper service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GophKeeper_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gophkeeper.GophKeeper",
+	HandlerType: (*GophKeeperServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SignIn",
+			Handler:    _GophKeeper_SignIn_Handler,
+		},
+		{
+			MethodName: "SignUp",
+			Handler:    _GophKeeper_SignUp_Handler,
+		},
+		{
+			MethodName: "SrpRegister",
+			Handler:    _GophKeeper_SrpRegister_Handler,
+		},
+		{
+			MethodName: "SrpStart",
+			Handler:    _GophKeeper_SrpStart_Handler,
+		},
+		{
+			MethodName: "SrpVerify",
+			Handler:    _GophKeeper_SrpVerify_Handler,
+		},
+		{
+			MethodName: "SyncDB",
+			Handler:    _GophKeeper_SyncDB_Handler,
+		},
+		{
+			MethodName: "ChangePassword",
+			Handler:    _GophKeeper_ChangePassword_Handler,
+		},
+		{
+			MethodName: "ChangeLogin",
+			Handler:    _GophKeeper_ChangeLogin_Handler,
+		},
+		{
+			MethodName: "DeleteAccount",
+			Handler:    _GophKeeper_DeleteAccount_Handler,
+		},
+		{
+			MethodName: "ListItems",
+			Handler:    _GophKeeper_ListItems_Handler,
+		},
+		{
+			MethodName: "SearchItems",
+			Handler:    _GophKeeper_SearchItems_Handler,
+		},
+		{
+			MethodName: "GetItem",
+			Handler:    _GophKeeper_GetItem_Handler,
+		},
+		{
+			MethodName: "ExportVault",
+			Handler:    _GophKeeper_ExportVault_Handler,
+		},
+		{
+			MethodName: "ImportVault",
+			Handler:    _GophKeeper_ImportVault_Handler,
+		},
+		{
+			MethodName: "ImportExternal",
+			Handler:    _GophKeeper_ImportExternal_Handler,
+		},
+		{
+			MethodName: "GetKeyBundles",
+			Handler:    _GophKeeper_GetKeyBundles_Handler,
+		},
+		{
+			MethodName: "PutKeyBundle",
+			Handler:    _GophKeeper_PutKeyBundle_Handler,
+		},
+		{
+			MethodName: "DeleteKeyBundle",
+			Handler:    _GophKeeper_DeleteKeyBundle_Handler,
+		},
+		{
+			MethodName: "RegenerateRecoveryCodes",
+			Handler:    _GophKeeper_RegenerateRecoveryCodes_Handler,
+		},
+		{
+			MethodName: "RecoverAccount",
+			Handler:    _GophKeeper_RecoverAccount_Handler,
+		},
+		{
+			MethodName: "AddEmergencyContact",
+			Handler:    _GophKeeper_AddEmergencyContact_Handler,
+		},
+		{
+			MethodName: "RemoveEmergencyContact",
+			Handler:    _GophKeeper_RemoveEmergencyContact_Handler,
+		},
+		{
+			MethodName: "ListEmergencyContacts",
+			Handler:    _GophKeeper_ListEmergencyContacts_Handler,
+		},
+		{
+			MethodName: "RequestEmergencyAccess",
+			Handler:    _GophKeeper_RequestEmergencyAccess_Handler,
+		},
+		{
+			MethodName: "DenyEmergencyAccess",
+			Handler:    _GophKeeper_DenyEmergencyAccess_Handler,
+		},
+		{
+			MethodName: "GetEmergencyVault",
+			Handler:    _GophKeeper_GetEmergencyVault_Handler,
+		},
+		{
+			MethodName: "ListAccountEvents",
+			Handler:    _GophKeeper_ListAccountEvents_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchChanges",
+			Handler:       _GophKeeper_WatchChanges_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "gophkeeper/gophkeeper.proto",
+}
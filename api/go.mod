@@ -0,0 +1,3 @@
+module github.com/Dorrrke/goph-keeper-proto
+
+go 1.21.5
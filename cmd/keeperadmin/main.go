@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/backup"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+	"github.com/Dorrrke/GophKeeper-server/internal/storage"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+const usage = `Usage: keeperadmin [flags] <command> [arg]
+
+Commands:
+  list            list users with item counts and last activity
+  show LOGIN      show one user
+  lock LOGIN      lock the account and revoke its tokens
+  unlock LOGIN    unlock the account
+  revoke LOGIN    revoke all tokens issued to the user
+  delete LOGIN    delete the user and all of their data (requires -yes)
+  backup FILE     write a consistent backup of all users ("-" for stdout)
+  restore FILE    restore a backup into an empty database, or one user with -user
+                  (requires -yes; the user's current data is replaced)
+  verify FILE     check a backup's format and checksum without a database
+
+Flags:
+`
+
+// options - флаги командной строки.
+type options struct {
+	storagePath string
+	json        bool
+	yes         bool
+	timeout     time.Duration
+	keyFile     string
+	user        string
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.storagePath, "storage-path", os.Getenv("DATA_BASE_PATH"), "postgres connection string (env DATA_BASE_PATH)")
+	flag.BoolVar(&opts.json, "json", false, "print results as JSON")
+	flag.BoolVar(&opts.yes, "yes", false, "confirm destructive commands")
+	flag.DurationVar(&opts.timeout, "timeout", time.Minute, "command timeout (backup and restore: no timeout unless set)")
+	flag.StringVar(&opts.keyFile, "key-file", os.Getenv("BACKUP_KEY_FILE"), "backup encryption key file (env BACKUP_KEY_FILE)")
+	flag.StringVar(&opts.user, "user", "", "restore only this login")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(opts, flag.Args(), os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "keeperadmin:", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts options, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		flag.Usage()
+		return errors.New("command is required")
+	}
+	cmd, arg := args[0], args[1:]
+	if cmd != "list" && len(arg) != 1 {
+		return fmt.Errorf("%s: exactly one argument is required", cmd)
+	}
+	key, err := readKey(opts.keyFile)
+	if err != nil {
+		return err
+	}
+	if cmd == "verify" {
+		return verify(out, opts.json, arg[0], key)
+	}
+	if opts.storagePath == "" {
+		return errors.New("storage-path is required")
+	}
+
+	// Резервное копирование больших баз не ограничивается по времени,
+	// если -timeout не задан явно.
+	ctx := context.Background()
+	if !isBackupCommand(cmd) || isFlagSet("timeout") {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+	pool, err := pgxpool.New(ctx, opts.storagePath)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	zlog := zerolog.New(os.Stderr).Level(zerolog.WarnLevel)
+	stor := storage.New(pool, &zlog)
+
+	switch cmd {
+	case "list":
+		users, err := stor.UserSummaries(ctx, "")
+		if err != nil {
+			return err
+		}
+		return printUsers(out, opts.json, users)
+	case "show":
+		users, err := stor.UserSummaries(ctx, arg[0])
+		if err != nil {
+			return err
+		}
+		return printUsers(out, opts.json, users)
+	case "lock", "unlock", "revoke":
+		user, err := stor.GetUser(ctx, arg[0])
+		if err != nil {
+			return err
+		}
+		uID := int(user.UserID)
+		changed := true
+		switch cmd {
+		case "lock":
+			changed, err = stor.LockUser(ctx, uID)
+		case "unlock":
+			changed, err = stor.UnlockUser(ctx, uID)
+		case "revoke":
+			err = stor.RevokeTokens(ctx, uID)
+		}
+		if err != nil {
+			return err
+		}
+		return printResult(out, opts.json, cmd, user, changed)
+	case "delete":
+		if !opts.yes {
+			return errors.New("delete: pass -yes to confirm")
+		}
+		user, err := stor.GetUser(ctx, arg[0])
+		if err != nil {
+			return err
+		}
+		if err := stor.DeleteUser(ctx, int(user.UserID)); err != nil {
+			return err
+		}
+		return printResult(out, opts.json, cmd, user, true)
+	case "backup":
+		return writeBackup(ctx, out, opts.json, stor, arg[0], key)
+	case "restore":
+		if !opts.yes {
+			return errors.New("restore: pass -yes to confirm")
+		}
+		in, err := openInput(arg[0])
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		res, err := stor.Restore(ctx, in, key, opts.user)
+		if err != nil {
+			return err
+		}
+		if opts.json {
+			return json.NewEncoder(out).Encode(res)
+		}
+		_, err = fmt.Fprintf(out, "restored %d users, %s; skipped %d rows\n", res.Users, formatItems(res.Rows), res.Skipped)
+		return err
+	default:
+		flag.Usage()
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+func isBackupCommand(cmd string) bool {
+	return cmd == "backup" || cmd == "restore"
+}
+
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
+
+// readKey - ключ шифрования копии из файла без завершающего перевода строки;
+// nil, если файл не указан.
+func readKey(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key := bytes.TrimRight(data, "\r\n")
+	if len(key) < backup.MinKeyLen {
+		return nil, backup.ErrWeakKey
+	}
+	return key, nil
+}
+
+// writeBackup - запись копии во временный файл рядом с path и переименование
+// после успешного завершения.
+func writeBackup(ctx context.Context, out io.Writer, asJSON bool, stor *storage.KeepStorage, path string, key []byte) error {
+	if path == "-" {
+		_, err := stor.Backup(ctx, out, key)
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	meta, err := stor.Backup(ctx, f, key)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	if asJSON {
+		return json.NewEncoder(out).Encode(struct {
+			File string `json:"file"`
+			backup.Meta
+			Encrypted bool `json:"encrypted"`
+		}{path, meta, key != nil})
+	}
+	_, err = fmt.Fprintf(out, "backup %s: schema version %d, encrypted %t\n", path, meta.SchemaVersion, key != nil)
+	return err
+}
+
+// verify - полная проверка копии: формат, ключ и контрольная сумма.
+func verify(out io.Writer, asJSON bool, path string, key []byte) error {
+	in, err := openInput(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	r, err := backup.NewReader(in, key)
+	if err != nil {
+		return err
+	}
+	rows := make(map[string]int)
+	for {
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		rows[rec.Table]++
+	}
+	if asJSON {
+		return json.NewEncoder(out).Encode(struct {
+			backup.Meta
+			Rows map[string]int `json:"rows"`
+		}{r.Meta(), rows})
+	}
+	meta := r.Meta()
+	_, err = fmt.Fprintf(out, "backup ok: created %s, schema version %d, %s\n",
+		meta.Created.UTC().Format(time.RFC3339), meta.SchemaVersion, formatItems(rows))
+	return err
+}
+
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// result - итог команды, изменяющей учетную запись.
+type result struct {
+	Command string `json:"command"`
+	UserID  int64  `json:"id"`
+	Login   string `json:"login"`
+	// Changed - false, если учетная запись уже была в нужном состоянии.
+	Changed bool `json:"changed"`
+}
+
+func printResult(out io.Writer, asJSON bool, cmd string, user models.UserModel, changed bool) error {
+	res := result{Command: cmd, UserID: user.UserID, Login: user.Login, Changed: changed}
+	if asJSON {
+		return json.NewEncoder(out).Encode(res)
+	}
+	state := "done"
+	if !changed {
+		state = "no change"
+	}
+	_, err := fmt.Fprintf(out, "%s %s (id %d): %s\n", cmd, user.Login, user.UserID, state)
+	return err
+}
+
+func printUsers(out io.Writer, asJSON bool, users []models.UserSummary) error {
+	if asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(users)
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tLOGIN\tSRP\tLOCKED\tLAST LOGIN\tLAST ACTIVITY\tITEMS")
+	for _, u := range users {
+		fmt.Fprintf(w, "%d\t%s\t%t\t%s\t%s\t%s\t%s\n", u.UserID, u.Login, u.SRP,
+			formatTime(u.LockedAt), formatTime(u.LastLogin), formatTime(u.LastActivity), formatItems(u.Items))
+	}
+	return w.Flush()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatItems - число записей по типам в виде "card=2 login=5".
+func formatItems(counts map[string]int) string {
+	names := make([]string, 0, len(counts))
+	for name, n := range counts {
+		if n > 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "-"
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%d", name, counts[name]))
+	}
+	return strings.Join(parts, " ")
+}
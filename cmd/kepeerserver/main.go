@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"os"
 
 	"github.com/Dorrrke/GophKeeper-server/internal/config"
 	grpcserver "github.com/Dorrrke/GophKeeper-server/internal/grpc"
@@ -12,6 +13,7 @@ import (
 	"github.com/Dorrrke/GophKeeper-server/internal/storage"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 var (
@@ -41,28 +43,45 @@ func main() {
 	} else {
 		fmt.Printf("\n\tBuild commit: %s\n", buildCommit)
 	}
-	cfg := config.ReadConfig()
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
-	zlog := logger.SetupLogger(cfg.DebugFlag)
+	zlog := logger.SetupLogger(cfg.Log.Debug, cfg.Log.Level)
 
-	zlog.Debug().Str("db addr", cfg.DBPath).Msg("Creating a database connection")
-	conn, err := initDB(cfg.DBPath)
+	zlog.Debug().Msg("Creating a database connection")
+	conn, err := initDB(cfg.DB)
 	if err != nil {
 		zlog.Panic().Err(err).Msg("Database initialization error")
 		panic(err)
 	}
 	zlog.Debug().Msg("Storage initialization")
 	kStor := storage.New(conn, zlog)
+	go kStor.ListenChanges(context.Background())
 
 	zlog.Debug().Msg("Service initialization")
-	kService := service.New(*kStor, zlog)
+	kService := service.New(*kStor, cfg.Recovery, cfg.Limits, cfg.SRP, zlog)
 
 	zlog.Debug().Msg("gRPC server initialization")
-	grpcServer := grpc.NewServer()
-	grpcserver.RegisterGrpcServer(grpcServer, kService, zlog)
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(cfg.Limits.MaxRecvMsgSize),
+		grpc.MaxSendMsgSize(cfg.Limits.MaxSendMsgSize),
+	}
+	if cfg.Server.TLS.Enabled {
+		creds, err := credentials.NewServerTLSFromFile(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile)
+		if err != nil {
+			zlog.Panic().Err(err).Msg("Load TLS credentials error")
+			panic(err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	grpcServer := grpc.NewServer(opts...)
+	grpcserver.RegisterGrpcServer(grpcServer, kService, cfg, zlog)
 
-	zlog.Debug().Str("addr", cfg.ServerAddr).Msg("Create net connection")
-	l, err := net.Listen("tcp", cfg.ServerAddr)
+	zlog.Debug().Str("addr", cfg.Server.Addr).Msg("Create net connection")
+	l, err := net.Listen("tcp", cfg.Server.Addr)
 	if err != nil {
 		zlog.Panic().Err(err).Msg("Init listener error")
 		panic(err)
@@ -76,8 +95,18 @@ func main() {
 	}
 }
 
-func initDB(DBAddr string) (*pgxpool.Pool, error) {
-	pool, err := pgxpool.New(context.Background(), DBAddr)
+func initDB(cfg config.DBConfig) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	poolCfg.MaxConns = int32(cfg.MaxConns)
+	poolCfg.MinConns = int32(cfg.MinConns)
+	poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	poolCfg.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolCfg)
 	if err != nil {
 		return nil, err
 	}
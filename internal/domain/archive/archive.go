@@ -0,0 +1,205 @@
+// Package archive - формат зашифрованного архива хранилища для экспорта и импорта.
+//
+// Архив версии 1 состоит из заголовка и зашифрованного содержимого.
+// Все целые числа записываются в порядке big-endian.
+//
+//	смещение  размер  поле
+//	0         4       сигнатура "GKVA"
+//	4         1       версия формата (1)
+//	5         1       KDF: 1 - Argon2id
+//	6         4       Argon2id: число проходов (time)
+//	10        4       Argon2id: память в KiB
+//	14        1       Argon2id: число потоков
+//	15        16      соль KDF
+//	31        12      nonce AES-GCM
+//	43        ...     шифротекст AES-256-GCM
+//
+// Ключ шифрования (32 байта) получается из пароля пользователя функцией
+// Argon2id с параметрами и солью из заголовка. Заголовок целиком передается
+// в AES-GCM как дополнительные данные, поэтому его изменение обнаруживается
+// при расшифровке.
+//
+// Открытое содержимое - JSON-документ Vault, сжатый gzip. Бинарные значения
+// полей кодируются в base64 (стандартный алфавит с дополнением). Удаленные
+// записи в архив не попадают.
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+	"golang.org/x/crypto/argon2"
+)
+
+// Version - текущая версия формата архива.
+const Version = 1
+
+const (
+	magic = "GKVA"
+
+	kdfArgon2id = 1
+
+	saltSize   = 16
+	nonceSize  = 12
+	keySize    = 32
+	headerSize = 4 + 1 + 1 + 4 + 4 + 1 + saltSize + nonceSize
+
+	// Параметры Argon2id по рекомендации RFC 9106 для ограниченной памяти.
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+
+	// Ограничения параметров при чтении, чтобы чужой архив не занял всю память.
+	maxArgonTime   = 10
+	maxArgonMemory = 256 * 1024
+
+	// MinPassphraseLen - минимальная длина пароля архива.
+	MinPassphraseLen = 8
+)
+
+var (
+	ErrInvalidArchive     = errors.New(errText.InvalidArchiveError)
+	ErrUnsupportedVersion = errors.New(errText.UnsupportedArchiveError)
+	ErrWrongPassphrase    = errors.New(errText.WrongPassphraseError)
+	ErrWeakPassphrase     = errors.New(errText.WeakPassphraseError)
+)
+
+type header struct {
+	version uint8
+	kdf     uint8
+	time    uint32
+	memory  uint32
+	threads uint8
+	salt    []byte
+	nonce   []byte
+}
+
+func (h header) marshal() []byte {
+	buf := make([]byte, 0, headerSize)
+	buf = append(buf, magic...)
+	buf = append(buf, h.version, h.kdf)
+	buf = binary.BigEndian.AppendUint32(buf, h.time)
+	buf = binary.BigEndian.AppendUint32(buf, h.memory)
+	buf = append(buf, h.threads)
+	buf = append(buf, h.salt...)
+	buf = append(buf, h.nonce...)
+	return buf
+}
+
+func parseHeader(data []byte) (header, error) {
+	if len(data) < headerSize || string(data[:4]) != magic {
+		return header{}, ErrInvalidArchive
+	}
+	h := header{
+		version: data[4],
+		kdf:     data[5],
+		time:    binary.BigEndian.Uint32(data[6:10]),
+		memory:  binary.BigEndian.Uint32(data[10:14]),
+		threads: data[14],
+		salt:    data[15 : 15+saltSize],
+		nonce:   data[15+saltSize : headerSize],
+	}
+	if h.version != Version {
+		return header{}, fmt.Errorf("%w: %d", ErrUnsupportedVersion, h.version)
+	}
+	if h.kdf != kdfArgon2id || h.time == 0 || h.time > maxArgonTime ||
+		h.memory == 0 || h.memory > maxArgonMemory || h.threads == 0 {
+		return header{}, fmt.Errorf("%w: bad key derivation parameters", ErrInvalidArchive)
+	}
+	return h, nil
+}
+
+func (h header) key(passphrase []byte) []byte {
+	return argon2.IDKey(passphrase, h.salt, h.time, h.memory, h.threads, keySize)
+}
+
+// Seal - сериализация и шифрование хранилища ключом из пароля.
+func Seal(v Vault, passphrase []byte) ([]byte, error) {
+	if len(passphrase) < MinPassphraseLen {
+		return nil, ErrWeakPassphrase
+	}
+	v.Version = Version
+
+	var plain bytes.Buffer
+	zw := gzip.NewWriter(&plain)
+	if err := json.NewEncoder(zw).Encode(v); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	h := header{
+		version: Version,
+		kdf:     kdfArgon2id,
+		time:    argonTime,
+		memory:  argonMemory,
+		threads: argonThreads,
+		salt:    make([]byte, saltSize),
+		nonce:   make([]byte, nonceSize),
+	}
+	if _, err := rand.Read(h.salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(h.nonce); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(h.key(passphrase))
+	if err != nil {
+		return nil, err
+	}
+	hdr := h.marshal()
+	return aead.Seal(hdr, h.nonce, plain.Bytes(), hdr), nil
+}
+
+// Open - расшифровка и разбор архива. maxSize ограничивает размер
+// распакованного содержимого.
+func Open(data, passphrase []byte, maxSize int64) (Vault, error) {
+	h, err := parseHeader(data)
+	if err != nil {
+		return Vault{}, err
+	}
+	aead, err := newAEAD(h.key(passphrase))
+	if err != nil {
+		return Vault{}, err
+	}
+	plain, err := aead.Open(nil, h.nonce, data[headerSize:], data[:headerSize])
+	if err != nil {
+		return Vault{}, ErrWrongPassphrase
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(plain))
+	if err != nil {
+		return Vault{}, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	defer zr.Close()
+	lr := &io.LimitedReader{R: zr, N: maxSize + 1}
+	var v Vault
+	if err := json.NewDecoder(lr).Decode(&v); err != nil {
+		if lr.N <= 0 {
+			return Vault{}, fmt.Errorf("%w: content exceeds %d bytes", ErrInvalidArchive, maxSize)
+		}
+		return Vault{}, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	if v.Version != int(h.version) {
+		return Vault{}, fmt.Errorf("%w: content version %d", ErrInvalidArchive, v.Version)
+	}
+	return v, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
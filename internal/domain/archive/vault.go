@@ -0,0 +1,133 @@
+package archive
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+	"github.com/google/uuid"
+)
+
+// Vault - открытое содержимое архива.
+type Vault struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	Items   []Entry   `json:"items"`
+}
+
+// Entry - запись хранилища в архиве. Type - имя типа из реестра items,
+// Values - значения полей типа; бинарные значения в base64.
+type Entry struct {
+	Type         string              `json:"type"`
+	ID           string              `json:"id"`
+	Name         string              `json:"name"`
+	FolderID     string              `json:"folder_id,omitempty"`
+	Tags         []string            `json:"tags,omitempty"`
+	CustomFields []items.CustomField `json:"custom_fields,omitempty"`
+	Values       map[string]string   `json:"values,omitempty"`
+	Updated      time.Time           `json:"updated"`
+}
+
+// DuplicatePolicy - поведение импорта при совпадении имени записи
+// с существующей записью того же типа.
+type DuplicatePolicy int
+
+const (
+	// Skip - существующая запись остается без изменений.
+	Skip DuplicatePolicy = iota
+	// Overwrite - существующая запись заменяется записью из архива.
+	Overwrite
+	// KeepBoth - запись из архива добавляется с суффиксом в имени.
+	KeepBoth
+)
+
+// ImportResult - итог импорта.
+type ImportResult struct {
+	Created int
+	Updated int
+	Skipped int
+}
+
+// FromItems - содержимое архива из записей хранилища. Удаленные записи
+// пропускаются.
+func FromItems(set items.Set) Vault {
+	v := Vault{Version: Version, Created: time.Now().UTC()}
+	for _, t := range items.Types() {
+		for _, it := range set[t.Name] {
+			if it.Deleted {
+				continue
+			}
+			e := Entry{
+				Type:         t.Name,
+				ID:           it.ID,
+				Name:         it.Name,
+				FolderID:     it.FolderID,
+				Tags:         it.Tags,
+				CustomFields: it.CustomFields,
+				Values:       make(map[string]string, len(t.Fields)),
+				Updated:      it.Updated.UTC(),
+			}
+			for _, f := range t.Fields {
+				// Значения ReadOnly назначаются заново при импорте.
+				if f.ReadOnly {
+					continue
+				}
+				if f.Kind == items.KindBytes {
+					e.Values[f.Name] = base64.StdEncoding.EncodeToString(it.Bytes(f.Name))
+				} else {
+					e.Values[f.Name] = it.String(f.Name)
+				}
+			}
+			v.Items = append(v.Items, e)
+		}
+	}
+	return v
+}
+
+// ToItems - записи архива для пользователя uID. Каждая запись проверяется
+// так же, как при синхронизации.
+func (v Vault) ToItems(uID int) (items.Set, error) {
+	set := make(items.Set)
+	for i, e := range v.Items {
+		t, ok := items.Lookup(e.Type)
+		if !ok {
+			return nil, fmt.Errorf("%w: item %d has unknown type %q", ErrInvalidArchive, i, e.Type)
+		}
+		if _, err := uuid.Parse(e.ID); err != nil {
+			return nil, fmt.Errorf("%w: %s %q has invalid id %q", ErrInvalidArchive, t.Name, e.Name, e.ID)
+		}
+		it := items.Item{
+			ID:      e.ID,
+			Type:    t.Name,
+			UserID:  uID,
+			Name:    e.Name,
+			Values:  make(map[string]any, len(t.Fields)),
+			Updated: e.Updated,
+		}
+		if t.Meta {
+			it.FolderID = e.FolderID
+			it.Tags = e.Tags
+			it.CustomFields = e.CustomFields
+		}
+		for _, f := range t.Fields {
+			if f.ReadOnly {
+				continue
+			}
+			if f.Kind != items.KindBytes {
+				it.Values[f.Name] = e.Values[f.Name]
+				continue
+			}
+			b, err := base64.StdEncoding.DecodeString(e.Values[f.Name])
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s %q: field %s is not base64", ErrInvalidArchive, t.Name, e.Name, f.Name)
+			}
+			it.Values[f.Name] = b
+		}
+		if err := t.Check(&it); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		set[t.Name] = append(set[t.Name], it)
+	}
+	return set, nil
+}
@@ -0,0 +1,461 @@
+// Package backup - формат логической резервной копии базы сервера.
+//
+// Копия версии 1 состоит из заголовка, последовательности кадров и
+// контрольной суммы. Все целые числа записываются в порядке big-endian.
+//
+//	смещение  размер  поле
+//	0         4       сигнатура "GKBK"
+//	4         1       версия формата (1)
+//	5         1       шифрование: 0 - нет, 1 - AES-256-GCM с ключом Argon2id
+//	6         4       Argon2id: число проходов (только при шифровании)
+//	10        4       Argon2id: память в KiB
+//	14        1       Argon2id: число потоков
+//	15        16      соль KDF
+//	31        4       префикс nonce
+//
+// Кадр: флаг (1 байт, 1 - последний кадр), длина данных (4 байта), данные.
+// Данные кадров вместе образуют поток gzip; при шифровании данные каждого
+// кадра запечатаны AES-GCM с nonce = префикс | номер кадра (8 байт) и
+// дополнительными данными заголовок | флаг, поэтому перестановка, удаление
+// и обрезка кадров обнаруживаются. После последнего кадра записывается
+// SHA-256 всех предыдущих байт файла.
+//
+// Распакованное содержимое - JSON-строки: первая - Meta, далее Record
+// по одной строке таблицы.
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+	"golang.org/x/crypto/argon2"
+)
+
+// Version - текущая версия формата.
+const Version = 1
+
+const (
+	magic = "GKBK"
+
+	encNone   = 0
+	encAESGCM = 1
+
+	saltSize        = 16
+	noncePrefixSize = 4
+	keySize         = 32
+	plainHeaderSize = 4 + 1 + 1
+	encHeaderSize   = plainHeaderSize + 4 + 4 + 1 + saltSize + noncePrefixSize
+
+	argonTime      = 3
+	argonMemory    = 64 * 1024
+	argonThreads   = 4
+	maxArgonTime   = 10
+	maxArgonMemory = 256 * 1024
+
+	// chunkSize - размер открытых данных кадра.
+	chunkSize = 64 * 1024
+	// maxFrameSize - предел длины кадра при чтении с учетом тега AES-GCM.
+	maxFrameSize = chunkSize + 16
+
+	flagFinal = 1
+
+	// MinKeyLen - минимальная длина ключа шифрования.
+	MinKeyLen = 16
+)
+
+var (
+	ErrInvalidBackup      = errors.New(errText.InvalidBackupError)
+	ErrUnsupportedVersion = errors.New(errText.UnsupportedBackupError)
+	ErrChecksumMismatch   = errors.New(errText.BackupChecksumError)
+	ErrWrongKey           = errors.New(errText.WrongBackupKeyError)
+	ErrKeyRequired        = errors.New(errText.BackupKeyRequiredError)
+	ErrWeakKey            = errors.New(errText.WeakBackupKeyError)
+)
+
+// Meta - описание копии, первая строка содержимого.
+type Meta struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	// SchemaVersion - версия миграций базы, с которой снята копия.
+	SchemaVersion uint `json:"schema_version"`
+}
+
+// Record - строка таблицы в виде JSON-объекта колонок.
+type Record struct {
+	Table string          `json:"table"`
+	Row   json.RawMessage `json:"row"`
+}
+
+type header struct {
+	enc         uint8
+	time        uint32
+	memory      uint32
+	threads     uint8
+	salt        []byte
+	noncePrefix []byte
+}
+
+func (h header) marshal() []byte {
+	buf := make([]byte, 0, encHeaderSize)
+	buf = append(buf, magic...)
+	buf = append(buf, Version, h.enc)
+	if h.enc == encNone {
+		return buf
+	}
+	buf = binary.BigEndian.AppendUint32(buf, h.time)
+	buf = binary.BigEndian.AppendUint32(buf, h.memory)
+	buf = append(buf, h.threads)
+	buf = append(buf, h.salt...)
+	buf = append(buf, h.noncePrefix...)
+	return buf
+}
+
+func readHeader(r io.Reader) (header, []byte, error) {
+	buf := make([]byte, plainHeaderSize, encHeaderSize)
+	if _, err := io.ReadFull(r, buf); err != nil || string(buf[:4]) != magic {
+		return header{}, nil, ErrInvalidBackup
+	}
+	if buf[4] != Version {
+		return header{}, nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, buf[4])
+	}
+	h := header{enc: buf[5]}
+	switch h.enc {
+	case encNone:
+		return h, buf, nil
+	case encAESGCM:
+	default:
+		return header{}, nil, fmt.Errorf("%w: unknown encryption %d", ErrInvalidBackup, h.enc)
+	}
+	buf = buf[:encHeaderSize]
+	if _, err := io.ReadFull(r, buf[plainHeaderSize:]); err != nil {
+		return header{}, nil, ErrInvalidBackup
+	}
+	h.time = binary.BigEndian.Uint32(buf[6:10])
+	h.memory = binary.BigEndian.Uint32(buf[10:14])
+	h.threads = buf[14]
+	h.salt = buf[15 : 15+saltSize]
+	h.noncePrefix = buf[15+saltSize : encHeaderSize]
+	if h.time == 0 || h.time > maxArgonTime || h.memory == 0 || h.memory > maxArgonMemory || h.threads == 0 {
+		return header{}, nil, fmt.Errorf("%w: bad key derivation parameters", ErrInvalidBackup)
+	}
+	return h, buf, nil
+}
+
+func (h header) aead(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(argon2.IDKey(key, h.salt, h.time, h.memory, h.threads, keySize))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// frameCodec - общие для записи и чтения кадров параметры.
+type frameCodec struct {
+	hdr     []byte
+	prefix  []byte
+	aead    cipher.AEAD
+	counter uint64
+}
+
+func (c *frameCodec) nonce() []byte {
+	n := make([]byte, 0, noncePrefixSize+8)
+	n = append(n, c.prefix...)
+	return binary.BigEndian.AppendUint64(n, c.counter)
+}
+
+func (c *frameCodec) ad(flag byte) []byte {
+	return append(bytes.Clone(c.hdr), flag)
+}
+
+// Writer - запись копии.
+type Writer struct {
+	out    *bufio.Writer
+	sum    hash.Hash
+	codec  frameCodec
+	buf    []byte
+	zw     *gzip.Writer
+	enc    *json.Encoder
+	closed bool
+}
+
+// NewWriter - начало копии в w. При непустом key содержимое шифруется.
+func NewWriter(w io.Writer, key []byte, meta Meta) (*Writer, error) {
+	h := header{enc: encNone}
+	if key != nil {
+		if len(key) < MinKeyLen {
+			return nil, ErrWeakKey
+		}
+		h = header{
+			enc:         encAESGCM,
+			time:        argonTime,
+			memory:      argonMemory,
+			threads:     argonThreads,
+			salt:        make([]byte, saltSize),
+			noncePrefix: make([]byte, noncePrefixSize),
+		}
+		if _, err := rand.Read(h.salt); err != nil {
+			return nil, err
+		}
+		if _, err := rand.Read(h.noncePrefix); err != nil {
+			return nil, err
+		}
+	}
+	bw := &Writer{
+		out: bufio.NewWriter(w),
+		sum: sha256.New(),
+		buf: make([]byte, 0, chunkSize),
+	}
+	bw.codec.hdr = h.marshal()
+	bw.codec.prefix = h.noncePrefix
+	if key != nil {
+		aead, err := h.aead(key)
+		if err != nil {
+			return nil, err
+		}
+		bw.codec.aead = aead
+	}
+	if err := bw.raw(bw.codec.hdr); err != nil {
+		return nil, err
+	}
+	bw.zw = gzip.NewWriter(chunkWriter{bw})
+	bw.enc = json.NewEncoder(bw.zw)
+
+	meta.Version = Version
+	if err := bw.enc.Encode(meta); err != nil {
+		return nil, err
+	}
+	return bw, nil
+}
+
+// Write - запись строки таблицы.
+func (w *Writer) Write(rec Record) error {
+	return w.enc.Encode(rec)
+}
+
+// Close - завершение копии: последний кадр и контрольная сумма. Writer,
+// переданный в NewWriter, не закрывается.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if err := w.zw.Close(); err != nil {
+		return err
+	}
+	if err := w.frame(flagFinal); err != nil {
+		return err
+	}
+	if _, err := w.out.Write(w.sum.Sum(nil)); err != nil {
+		return err
+	}
+	return w.out.Flush()
+}
+
+func (w *Writer) raw(p []byte) error {
+	w.sum.Write(p)
+	_, err := w.out.Write(p)
+	return err
+}
+
+// frame - запись накопленных данных одним кадром.
+func (w *Writer) frame(flag byte) error {
+	data := w.buf
+	if w.codec.aead != nil {
+		data = w.codec.aead.Seal(nil, w.codec.nonce(), data, w.codec.ad(flag))
+		w.codec.counter++
+	}
+	hdr := []byte{flag, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(hdr[1:], uint32(len(data)))
+	if err := w.raw(hdr); err != nil {
+		return err
+	}
+	w.buf = w.buf[:0]
+	return w.raw(data)
+}
+
+// chunkWriter - разбиение потока gzip на кадры.
+type chunkWriter struct {
+	w *Writer
+}
+
+func (c chunkWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		free := chunkSize - len(c.w.buf)
+		if free > len(p) {
+			free = len(p)
+		}
+		c.w.buf = append(c.w.buf, p[:free]...)
+		p = p[free:]
+		if len(c.w.buf) == chunkSize {
+			if err := c.w.frame(0); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Reader - чтение и проверка копии.
+type Reader struct {
+	meta   Meta
+	frames *frameReader
+	dec    *json.Decoder
+}
+
+// NewReader - разбор заголовка и описания копии. key нужен только для
+// зашифрованной копии.
+func NewReader(r io.Reader, key []byte) (*Reader, error) {
+	in := bufio.NewReader(r)
+	h, hdr, err := readHeader(in)
+	if err != nil {
+		return nil, err
+	}
+	fr := &frameReader{in: in, sum: sha256.New()}
+	fr.sum.Write(hdr)
+	fr.codec.hdr = hdr
+	fr.codec.prefix = h.noncePrefix
+	if h.enc == encAESGCM {
+		if len(key) == 0 {
+			return nil, ErrKeyRequired
+		}
+		if fr.codec.aead, err = h.aead(key); err != nil {
+			return nil, err
+		}
+	}
+	zr, err := gzip.NewReader(fr)
+	if err != nil {
+		return nil, wrapRead(err)
+	}
+	br := &Reader{frames: fr, dec: json.NewDecoder(zr)}
+	if err := br.dec.Decode(&br.meta); err != nil {
+		return nil, wrapRead(err)
+	}
+	if br.meta.Version != Version {
+		return nil, fmt.Errorf("%w: content version %d", ErrInvalidBackup, br.meta.Version)
+	}
+	return br, nil
+}
+
+// Meta - описание копии.
+func (r *Reader) Meta() Meta {
+	return r.meta
+}
+
+// Next - следующая строка таблицы. После последней строки проверяется
+// контрольная сумма и возвращается io.EOF; данные копии можно считать
+// подлинными только после этого.
+func (r *Reader) Next() (Record, error) {
+	var rec Record
+	err := r.dec.Decode(&rec)
+	if errors.Is(err, io.EOF) {
+		if _, err := io.Copy(io.Discard, r.frames); err != nil {
+			return Record{}, wrapRead(err)
+		}
+		return Record{}, io.EOF
+	}
+	if err != nil {
+		return Record{}, wrapRead(err)
+	}
+	if rec.Table == "" || len(rec.Row) == 0 {
+		return Record{}, fmt.Errorf("%w: empty record", ErrInvalidBackup)
+	}
+	return rec, nil
+}
+
+func wrapRead(err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidBackup), errors.Is(err, ErrChecksumMismatch), errors.Is(err, ErrWrongKey):
+		return err
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return fmt.Errorf("%w: truncated", ErrInvalidBackup)
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+}
+
+// frameReader - открытые данные кадров с проверкой контрольной суммы.
+type frameReader struct {
+	in    *bufio.Reader
+	sum   hash.Hash
+	codec frameCodec
+	buf   []byte
+	final bool
+	done  bool
+}
+
+func (f *frameReader) Read(p []byte) (int, error) {
+	for len(f.buf) == 0 {
+		if f.done {
+			return 0, io.EOF
+		}
+		if f.final {
+			if err := f.verify(); err != nil {
+				return 0, err
+			}
+			f.done = true
+			return 0, io.EOF
+		}
+		if err := f.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, f.buf)
+	f.buf = f.buf[n:]
+	return n, nil
+}
+
+func (f *frameReader) next() error {
+	hdr := make([]byte, 5)
+	if _, err := io.ReadFull(f.in, hdr); err != nil {
+		return fmt.Errorf("%w: truncated", ErrInvalidBackup)
+	}
+	flag, size := hdr[0], binary.BigEndian.Uint32(hdr[1:])
+	if flag&^flagFinal != 0 || size > maxFrameSize {
+		return fmt.Errorf("%w: bad frame", ErrInvalidBackup)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(f.in, data); err != nil {
+		return fmt.Errorf("%w: truncated", ErrInvalidBackup)
+	}
+	f.sum.Write(hdr)
+	f.sum.Write(data)
+	if f.codec.aead != nil {
+		plain, err := f.codec.aead.Open(data[:0], f.codec.nonce(), data, f.codec.ad(flag))
+		if err != nil {
+			return ErrWrongKey
+		}
+		f.codec.counter++
+		data = plain
+	}
+	f.buf = data
+	f.final = flag == flagFinal
+	return nil
+}
+
+func (f *frameReader) verify() error {
+	want := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(f.in, want); err != nil {
+		return fmt.Errorf("%w: truncated", ErrInvalidBackup)
+	}
+	if subtle.ConstantTimeCompare(want, f.sum.Sum(nil)) != 1 {
+		return ErrChecksumMismatch
+	}
+	if _, err := f.in.ReadByte(); err != io.EOF {
+		return fmt.Errorf("%w: trailing data", ErrInvalidBackup)
+	}
+	return nil
+}
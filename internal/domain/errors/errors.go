@@ -1,21 +1,72 @@
 package errors
 
 const (
-	InvalidAuthError             = "invalid login or password"
-	UserExistsError              = "user is alredy exists"
-	UserNotExistError            = "user not found"
-	CardExistsError              = "card is alredy exists"
-	LoginExistsError             = "login is alredy exists"
-	TextExistsError              = "text is alredy exists"
-	CardNotExistsError           = "card not found"
-	LoginNotExistsError          = "login not found"
-	TextNotExistsError           = "text not found"
-	InvalidPasswordError         = "invalid password"
-	DataDecryptError             = "could not decrypt data"
-	BinDataExistsError           = "bin data is alredy exists"
-	BinDataNotExistsError        = "bin data not found"
-	InvalidLoginUserError        = "invalid login/password pair; this user does not exist"
-	MetadataError                = "metadata does not exist"
-	MissingAuthorizationKeyError = "missing authorization key"
-	InvalidTokenError            = "invalid token"
+	InvalidAuthError                 = "invalid login or password"
+	UserExistsError                  = "user is alredy exists"
+	UserNotExistError                = "user not found"
+	CardExistsError                  = "card is alredy exists"
+	LoginExistsError                 = "login is alredy exists"
+	TextExistsError                  = "text is alredy exists"
+	CardNotExistsError               = "card not found"
+	LoginNotExistsError              = "login not found"
+	TextNotExistsError               = "text not found"
+	InvalidPasswordError             = "invalid password"
+	DataDecryptError                 = "could not decrypt data"
+	BinDataExistsError               = "bin data is alredy exists"
+	BinDataNotExistsError            = "bin data not found"
+	InvalidLoginUserError            = "invalid login/password pair; this user does not exist"
+	MetadataError                    = "metadata does not exist"
+	MissingAuthorizationKeyError     = "missing authorization key"
+	InvalidTokenError                = "invalid token"
+	TooManySyncItemsError            = "too many items in sync request"
+	InvalidIdempotencyKeyError       = "invalid idempotency key"
+	IdempotencyKeyReusedError        = "idempotency key was already used with a different request"
+	SyncInProgressError              = "sync with this idempotency key is still in progress; retry later"
+	SyncCursorNotExistError          = "sync page token expired; start a new sync"
+	SyncPageItemsError               = "sync page request must not contain items"
+	ConcurrentUpdateError            = "concurrent update conflict; retry the request"
+	FolderNotExistsError             = "folder not found"
+	ItemNotExistsError               = "item not found"
+	ItemIDConflictError              = "item id is already taken"
+	UnknownItemTypeError             = "unknown item type"
+	InvalidPageTokenError            = "invalid page token"
+	InvalidFilterError               = "invalid filter"
+	InvalidArchiveError              = "invalid vault archive"
+	UnsupportedArchiveError          = "unsupported vault archive version"
+	WrongPassphraseError             = "wrong passphrase or corrupted archive"
+	WeakPassphraseError              = "passphrase is too short"
+	InvalidBackupError               = "invalid backup file"
+	UnsupportedBackupError           = "unsupported backup version"
+	BackupChecksumError              = "backup checksum mismatch"
+	WrongBackupKeyError              = "wrong backup key or corrupted backup"
+	BackupKeyRequiredError           = "backup is encrypted; a key is required"
+	WeakBackupKeyError               = "backup key is too short"
+	BackupSchemaMismatchError        = "backup schema version does not match the database"
+	RestoreTargetNotEmptyError       = "full restore requires a database without users"
+	UserNotInBackupError             = "user not found in backup"
+	UnknownDuplicatePolicyError      = "unknown duplicate policy"
+	InvalidImportFileError           = "invalid import file"
+	InvalidCSVMappingError           = "invalid csv column mapping"
+	UnknownImportFormatError         = "unknown import format"
+	ImportTooLargeError              = "import data is too large"
+	TokenRevokedError                = "token has been revoked"
+	EmptyPasswordError               = "password must not be empty"
+	EmptyLoginError                  = "login must not be empty"
+	LoginMismatchError               = "login confirmation does not match"
+	SamePasswordError                = "new password must differ from the current one"
+	AccountLockedError               = "account is locked"
+	SRPInvalidPublicKeyError         = "invalid srp public value"
+	SRPProofMismatchError            = "srp proof does not match"
+	SRPSessionNotExistError          = "srp session not found or expired"
+	InvalidSRPVerifierError          = "invalid srp salt or verifier"
+	KeyBundleNotExistError           = "key bundle not found"
+	TooManyKeyBundlesError           = "too many key bundles"
+	InvalidKeyBundleError            = "invalid key bundle"
+	InvalidRecoveryCodeError         = "invalid or used recovery code"
+	EmergencyContactNotExistError    = "emergency contact not found"
+	EmergencyContactExistsError      = "emergency contact already exists"
+	SelfEmergencyContactError        = "cannot add yourself as an emergency contact"
+	InvalidEmergencyWaitError        = "emergency wait period is out of range"
+	EmergencyAccessNotRequestedError = "emergency access has not been requested"
+	EmergencyAccessNotGrantedError   = "emergency access has not been granted yet"
 )
@@ -0,0 +1,23 @@
+// Package events - события изменения записей для живой синхронизации.
+package events
+
+// Change - изменение одной записи.
+type Change struct {
+	Type    string `json:"t"`
+	ID      string `json:"i"`
+	Deleted bool   `json:"d,omitempty"`
+}
+
+// Batch - изменения, зафиксированные одной синхронизацией или импортом.
+// Передается между экземплярами сервера через NOTIFY, поэтому поля
+// сериализуются короткими именами.
+type Batch struct {
+	UserID int `json:"u"`
+	// Device - устройство-источник изменений; ему событие не отправляется.
+	Device string `json:"o,omitempty"`
+	// Revision - ревизия хранилища пользователя после изменений.
+	Revision int64    `json:"r"`
+	Changes  []Change `json:"c,omitempty"`
+	// Resync - список изменений неполон, клиенту нужна полная синхронизация.
+	Resync bool `json:"s,omitempty"`
+}
@@ -0,0 +1,157 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+)
+
+// Типы записей Bitwarden.
+const (
+	bwLogin      = 1
+	bwSecureNote = 2
+	bwCard       = 3
+	bwIdentity   = 4
+)
+
+// Типы произвольных полей Bitwarden.
+const (
+	bwFieldText    = 0
+	bwFieldHidden  = 1
+	bwFieldBoolean = 2
+	bwFieldLinked  = 3
+)
+
+type bwExport struct {
+	Encrypted bool       `json:"encrypted"`
+	Folders   []bwFolder `json:"folders"`
+	Items     []bwItem   `json:"items"`
+}
+
+type bwFolder struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type bwItem struct {
+	Type     int       `json:"type"`
+	Name     string    `json:"name"`
+	Notes    string    `json:"notes"`
+	FolderID string    `json:"folderId"`
+	Fields   []bwField `json:"fields"`
+	Login    *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Totp     string `json:"totp"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+	Card *struct {
+		CardholderName string `json:"cardholderName"`
+		Number         string `json:"number"`
+		ExpMonth       string `json:"expMonth"`
+		ExpYear        string `json:"expYear"`
+		Code           string `json:"code"`
+	} `json:"card"`
+}
+
+type bwField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Type  int    `json:"type"`
+}
+
+// Bitwarden - разбор незашифрованного JSON-экспорта Bitwarden. Логины, карты
+// и заметки переносятся в логины, карты и тексты; папки - в папки.
+func Bitwarden(data []byte) (Result, error) {
+	var export bwExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	if export.Encrypted {
+		return Result{}, fmt.Errorf("%w: encrypted Bitwarden exports are not supported", ErrInvalidFile)
+	}
+
+	b := newBuilder()
+	folders := make(map[string]string, len(export.Folders))
+	for _, f := range export.Folders {
+		folders[f.ID] = f.Name
+	}
+
+	for i, bi := range export.Items {
+		entry := bi.Name
+		if entry == "" {
+			entry = fmt.Sprintf("item %d", i+1)
+		}
+		it := items.Item{
+			Name:     bi.Name,
+			FolderID: b.folder(folders[bi.FolderID]),
+			Values:   make(map[string]any),
+		}
+		for _, f := range bi.Fields {
+			switch f.Type {
+			case bwFieldText:
+				it.CustomFields = append(it.CustomFields, items.CustomField{Name: f.Name, Type: items.CustomFieldText, Value: f.Value})
+			case bwFieldHidden:
+				it.CustomFields = append(it.CustomFields, items.CustomField{Name: f.Name, Type: items.CustomFieldHidden, Value: f.Value})
+			case bwFieldBoolean:
+				it.CustomFields = append(it.CustomFields, items.CustomField{Name: f.Name, Type: items.CustomFieldBoolean, Value: strings.ToLower(f.Value)})
+			}
+			// Связанные поля (bwFieldLinked) ссылаются на другие поля записи
+			// и не содержат значения.
+		}
+
+		switch bi.Type {
+		case bwLogin:
+			if bi.Login == nil {
+				b.skip(entry, "login item has no login data")
+				continue
+			}
+			it.Values["login"] = bi.Login.Username
+			it.Values["password"] = bi.Login.Password
+			for j, u := range bi.Login.URIs {
+				name := "url"
+				if j > 0 {
+					name = fmt.Sprintf("url %d", j+1)
+				}
+				it.CustomFields = append(it.CustomFields, urlField(name, u.URI))
+			}
+			if bi.Login.Totp != "" {
+				it.CustomFields = append(it.CustomFields, items.CustomField{Name: "totp", Type: items.CustomFieldHidden, Value: bi.Login.Totp})
+			}
+			if bi.Notes != "" {
+				it.CustomFields = append(it.CustomFields, items.CustomField{Name: "notes", Type: items.CustomFieldText, Value: bi.Notes})
+			}
+			b.add(entry, items.Login, it)
+		case bwSecureNote:
+			it.Values["data"] = bi.Notes
+			b.add(entry, items.Text, it)
+		case bwCard:
+			if bi.Card == nil {
+				b.skip(entry, "card item has no card data")
+				continue
+			}
+			date, err := cardDate(bi.Card.ExpMonth, bi.Card.ExpYear)
+			if err != nil {
+				b.skip(entry, "%v", err)
+				continue
+			}
+			it.Values["number"] = bi.Card.Number
+			it.Values["date"] = date
+			it.Values["cvv"] = bi.Card.Code
+			it.Values["cardholder"] = bi.Card.CardholderName
+			if bi.Notes != "" {
+				it.CustomFields = append(it.CustomFields, items.CustomField{Name: "notes", Type: items.CustomFieldText, Value: bi.Notes})
+			}
+			b.add(entry, items.Card, it)
+		case bwIdentity:
+			b.skip(entry, "identity items are not supported")
+		default:
+			b.skip(entry, "unknown item type %d", bi.Type)
+		}
+	}
+	return b.res, nil
+}
@@ -0,0 +1,89 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBitwarden(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		counts   map[string]int
+		skipped  []string
+		wantErr  error
+		checkRes func(t *testing.T, res Result)
+	}{
+		{
+			name: "login card and note",
+			data: `{"encrypted": false, "folders": [{"id": "f1", "name": "Work"}], "items": [
+				{"type": 1, "name": "mail", "folderId": "f1", "notes": "n",
+				 "login": {"username": "user", "password": "secret", "totp": "otp",
+				           "uris": [{"uri": "https://mail.example.com"}, {"uri": "example.com"}]},
+				 "fields": [{"name": "pin", "value": "1", "type": 1}, {"name": "linked", "type": 3}]},
+				{"type": 3, "name": "visa", "card": {"cardholderName": "J DOE", "number": "4111 1111 1111 1111",
+				 "expMonth": "4", "expYear": "2030", "code": "123"}},
+				{"type": 2, "name": "memo", "notes": "text"}]}`,
+			counts: map[string]int{"login": 1, "card": 1, "text": 1, "folder": 1},
+			checkRes: func(t *testing.T, res Result) {
+				login := res.Items["login"][0]
+				if login.FolderID != res.Items["folder"][0].ID {
+					t.Error("login is not in its folder")
+				}
+				// pin, url, url 2, totp, notes; связанное поле пропускается.
+				if len(login.CustomFields) != 5 {
+					t.Errorf("custom fields = %v", login.CustomFields)
+				}
+				card := res.Items["card"][0]
+				if card.String("date") != "04/30" || card.String("number") != "4111111111111111" {
+					t.Errorf("card values = %v", card.Values)
+				}
+			},
+		},
+		{
+			name: "skipped items",
+			data: `{"items": [
+				{"type": 1, "name": "no login"},
+				{"type": 3, "name": "no card"},
+				{"type": 3, "name": "bad date", "card": {"number": "4111111111111111", "expMonth": "13", "expYear": "30"}},
+				{"type": 3, "name": "bad number", "card": {"number": "4111111111111112", "expMonth": "1", "expYear": "30"}},
+				{"type": 4, "name": "identity"},
+				{"type": 9},
+				{"type": 2, "name": ""}]}`,
+			counts:  map[string]int{},
+			skipped: []string{"no login", "no card", "bad date", "bad number", "identity", "item 6", "item 7"},
+		},
+		{
+			name:    "encrypted export",
+			data:    `{"encrypted": true, "items": []}`,
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:    "invalid json",
+			data:    `{"items": [`,
+			wantErr: ErrInvalidFile,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Bitwarden([]byte(tt.data))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := counts(res); fmt.Sprint(got) != fmt.Sprint(tt.counts) {
+				t.Errorf("items = %v, want %v", got, tt.counts)
+			}
+			if got := skippedEntries(res); strings.Join(got, "|") != strings.Join(tt.skipped, "|") {
+				t.Errorf("skipped = %q, want %q (%v)", got, tt.skipped, res.Skipped)
+			}
+			if tt.checkRes != nil {
+				tt.checkRes(t, res)
+			}
+		})
+	}
+}
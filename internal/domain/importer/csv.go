@@ -0,0 +1,169 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+)
+
+// Общие цели сопоставления колонок CSV; остальные цели - поля типа записи
+// (например, login и password для логинов) или "field:<имя>" для
+// произвольного текстового поля.
+const (
+	csvName        = "name"
+	csvFolder      = "folder"
+	csvTags        = "tags"
+	csvURL         = "url"
+	csvNotes       = "notes"
+	csvFieldPrefix = "field:"
+)
+
+// CSVMapping - сопоставление колонок CSV полям записи.
+type CSVMapping struct {
+	// Type - тип создаваемых записей.
+	Type *items.Type
+	// Columns - заголовок колонки CSV для каждой цели.
+	Columns map[string]string
+	// Delimiter - разделитель колонок, по умолчанию запятая.
+	Delimiter rune
+}
+
+// CSV - разбор CSV-файла с заголовком. Каждая строка становится записью
+// типа mapping.Type. Бинарные данные в колонке data кодируются в base64,
+// срок действия карты - MM/YY, MM/YYYY или YYYY-MM.
+func CSV(data []byte, mapping CSVMapping) (Result, error) {
+	if mapping.Type == nil || !mapping.Type.Meta {
+		return Result{}, fmt.Errorf("%w: unsupported item type", ErrInvalidMapping)
+	}
+	if mapping.Columns[csvName] == "" {
+		return Result{}, fmt.Errorf("%w: column for %q is required", ErrInvalidMapping, csvName)
+	}
+
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	if mapping.Delimiter != 0 {
+		r.Comma = mapping.Delimiter
+	}
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: read header: %v", ErrInvalidFile, err)
+	}
+
+	columns := make(map[string]int, len(mapping.Columns))
+	for target, name := range mapping.Columns {
+		if !validTarget(mapping.Type, target) {
+			return Result{}, fmt.Errorf("%w: unknown target %q for %s", ErrInvalidMapping, target, mapping.Type.Name)
+		}
+		idx := slices.Index(header, name)
+		if idx < 0 {
+			return Result{}, fmt.Errorf("%w: column %q not found in header", ErrInvalidMapping, name)
+		}
+		columns[target] = idx
+	}
+
+	b := newBuilder()
+	for row := 2; ; row++ {
+		record, err := r.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return Result{}, fmt.Errorf("%w: row %d: %v", ErrInvalidFile, row, err)
+		}
+		csvRow(b, fmt.Sprintf("row %d", row), mapping.Type, columns, record)
+	}
+	return b.res, nil
+}
+
+func validTarget(t *items.Type, target string) bool {
+	switch target {
+	case csvName, csvFolder, csvTags, csvURL, csvNotes:
+		return true
+	}
+	if name, ok := strings.CutPrefix(target, csvFieldPrefix); ok {
+		return name != ""
+	}
+	return slices.ContainsFunc(t.Fields, func(f items.Field) bool { return f.Name == target })
+}
+
+func csvRow(b *builder, entry string, t *items.Type, columns map[string]int, record []string) {
+	get := func(target string) string {
+		idx, ok := columns[target]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return record[idx]
+	}
+	if name := get(csvName); name != "" {
+		entry = fmt.Sprintf("%s (%s)", entry, name)
+	}
+
+	it := items.Item{
+		Name:     get(csvName),
+		FolderID: b.folder(get(csvFolder)),
+		Tags:     splitTags(get(csvTags)),
+		Values:   make(map[string]any, len(t.Fields)),
+	}
+	if v := get(csvURL); v != "" {
+		it.CustomFields = append(it.CustomFields, urlField("url", v))
+	}
+	for _, f := range t.Fields {
+		v := get(f.Name)
+		switch {
+		case f.Kind == items.KindBytes:
+			data, err := base64.StdEncoding.DecodeString(v)
+			if err != nil {
+				b.skip(entry, "%s is not base64", f.Name)
+				return
+			}
+			it.Values[f.Name] = data
+		case t == items.Card && f.Name == "date":
+			date, err := parseCardDate(v)
+			if err != nil {
+				b.skip(entry, "%v", err)
+				return
+			}
+			it.Values[f.Name] = date
+		default:
+			it.Values[f.Name] = v
+		}
+	}
+	for target, idx := range columns {
+		name, ok := strings.CutPrefix(target, csvFieldPrefix)
+		if !ok || idx >= len(record) || record[idx] == "" {
+			continue
+		}
+		it.CustomFields = append(it.CustomFields, items.CustomField{Name: name, Type: items.CustomFieldText, Value: record[idx]})
+	}
+	slices.SortFunc(it.CustomFields, func(a, b items.CustomField) int { return strings.Compare(a.Name, b.Name) })
+
+	if notes := get(csvNotes); notes != "" {
+		if t == items.Text && it.String("data") == "" {
+			it.Values["data"] = notes
+		} else {
+			it.CustomFields = append(it.CustomFields, items.CustomField{Name: "notes", Type: items.CustomFieldText, Value: notes})
+		}
+	}
+	b.add(entry, t, it)
+}
+
+// parseCardDate - срок действия карты из MM/YY, MM/YYYY или YYYY-MM.
+func parseCardDate(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == '/' || r == '-' || r == '.' })
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid expiration date %q", s)
+	}
+	if utf8.RuneCountInString(parts[0]) == 4 {
+		parts[0], parts[1] = parts[1], parts[0]
+	}
+	return cardDate(parts[0], parts[1])
+}
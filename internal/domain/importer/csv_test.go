@@ -0,0 +1,153 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+)
+
+func TestCSV(t *testing.T) {
+	logins := CSVMapping{Type: items.Login, Columns: map[string]string{
+		"name": "Title", "login": "User", "password": "Pass", "url": "URL", "folder": "Group", "field:otp": "OTP",
+	}}
+	tests := []struct {
+		name     string
+		data     string
+		mapping  CSVMapping
+		counts   map[string]int
+		skipped  []string
+		wantErr  error
+		checkRes func(t *testing.T, res Result)
+	}{
+		{
+			name: "logins",
+			data: "\xef\xbb\xbfTitle,User,Pass,URL,Group,OTP\n" +
+				"mail,user,secret,https://mail.example.com,Work,123\n" +
+				",nobody,x,,,\n" +
+				"short\n",
+			mapping: logins,
+			counts:  map[string]int{"login": 2, "folder": 1},
+			skipped: []string{"row 3"},
+			checkRes: func(t *testing.T, res Result) {
+				it := res.Items["login"][0]
+				if it.String("login") != "user" || it.String("password") != "secret" {
+					t.Errorf("values = %v", it.Values)
+				}
+				if len(it.CustomFields) != 2 || it.CustomFields[0].Name != "otp" || it.CustomFields[1].Type != items.CustomFieldURL {
+					t.Errorf("custom fields = %v", it.CustomFields)
+				}
+				if it.FolderID != res.Items["folder"][0].ID {
+					t.Error("login is not in its folder")
+				}
+			},
+		},
+		{
+			name: "cards",
+			data: "name;number;exp;cvv\n" +
+				"visa;4111111111111111;2030-04;123\n" +
+				"bad date;4111111111111111;13/30;123\n" +
+				"bad number;4111111111111112;04/30;123\n",
+			mapping: CSVMapping{Type: items.Card, Delimiter: ';', Columns: map[string]string{
+				"name": "name", "number": "number", "date": "exp", "cvv": "cvv",
+			}},
+			counts:  map[string]int{"card": 1},
+			skipped: []string{"row 3 (bad date)", "row 4 (bad number)"},
+			checkRes: func(t *testing.T, res Result) {
+				if got := res.Items["card"][0].String("date"); got != "04/30" {
+					t.Errorf("date = %q, want 04/30", got)
+				}
+			},
+		},
+		{
+			name: "binary data",
+			data: "name,data\nok,aGVsbG8=\nbad,***\n",
+			mapping: CSVMapping{Type: items.Binary, Columns: map[string]string{
+				"name": "name", "data": "data",
+			}},
+			counts:  map[string]int{"binary": 1},
+			skipped: []string{"row 3 (bad)"},
+		},
+		{
+			name:    "name column required",
+			data:    "User\nuser\n",
+			mapping: CSVMapping{Type: items.Login, Columns: map[string]string{"login": "User"}},
+			wantErr: ErrInvalidMapping,
+		},
+		{
+			name:    "unknown target",
+			data:    "Title\nx\n",
+			mapping: CSVMapping{Type: items.Login, Columns: map[string]string{"name": "Title", "cvv": "Title"}},
+			wantErr: ErrInvalidMapping,
+		},
+		{
+			name:    "missing column",
+			data:    "Title\nx\n",
+			mapping: logins,
+			wantErr: ErrInvalidMapping,
+		},
+		{
+			name:    "client-encrypted type",
+			data:    "Title\nx\n",
+			mapping: CSVMapping{Type: items.Encrypted, Columns: map[string]string{"name": "Title"}},
+			wantErr: ErrInvalidMapping,
+		},
+		{
+			name:    "broken quoting",
+			data:    "Title,User,Pass,URL,Group,OTP\n\"mail,user\n",
+			mapping: logins,
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:    "empty file",
+			data:    "",
+			mapping: logins,
+			wantErr: ErrInvalidFile,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := CSV([]byte(tt.data), tt.mapping)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := counts(res); fmt.Sprint(got) != fmt.Sprint(tt.counts) {
+				t.Errorf("items = %v, want %v", got, tt.counts)
+			}
+			if got := skippedEntries(res); strings.Join(got, "|") != strings.Join(tt.skipped, "|") {
+				t.Errorf("skipped = %q, want %q (%v)", got, tt.skipped, res.Skipped)
+			}
+			if tt.checkRes != nil {
+				tt.checkRes(t, res)
+			}
+		})
+	}
+}
+
+func TestParseCardDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"04/30", "04/30", true},
+		{"4/2030", "04/30", true},
+		{"2030-04", "04/30", true},
+		{" 12.31 ", "12/31", true},
+		{"00/30", "", false},
+		{"04/300", "", false},
+		{"0430", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, err := parseCardDate(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseCardDate(%q) = %q, %v; want %q, ok %v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
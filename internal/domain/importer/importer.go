@@ -0,0 +1,133 @@
+// Package importer - разбор экспортов других менеджеров паролей (KeePass 2 XML,
+// Bitwarden JSON, CSV) в записи хранилища.
+//
+// Записи, которые не удалось перенести, не прерывают импорт: они попадают
+// в Result.Skipped с причиной. Ошибка возвращается, только если файл
+// не удается разобрать целиком или распакованные данные превышают предел.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidFile    = errors.New(errText.InvalidImportFileError)
+	ErrInvalidMapping = errors.New(errText.InvalidCSVMappingError)
+	ErrImportTooLarge = errors.New(errText.ImportTooLargeError)
+)
+
+// Skipped - запись исходного файла, которая не была импортирована.
+type Skipped struct {
+	// Entry - название записи в исходном файле или номер строки.
+	Entry  string
+	Reason string
+}
+
+// Result - записи, готовые к импорту, и пропущенные записи.
+type Result struct {
+	Items   items.Set
+	Skipped []Skipped
+}
+
+// builder - накопление результата: папки по имени, проверка записей.
+type builder struct {
+	res     Result
+	folders map[string]string
+	now     time.Time
+}
+
+func newBuilder() *builder {
+	return &builder{
+		res:     Result{Items: make(items.Set)},
+		folders: make(map[string]string),
+		now:     time.Now().UTC(),
+	}
+}
+
+func (b *builder) skip(entry, format string, args ...any) {
+	b.res.Skipped = append(b.res.Skipped, Skipped{Entry: entry, Reason: fmt.Sprintf(format, args...)})
+}
+
+// folder - id папки с именем name; папка создается при первом обращении.
+func (b *builder) folder(name string) string {
+	name = truncate(strings.TrimSpace(name), items.MaxNameLen)
+	if name == "" {
+		return ""
+	}
+	if id, ok := b.folders[name]; ok {
+		return id
+	}
+	id := uuid.NewString()
+	b.folders[name] = id
+	b.res.Items[items.Folder.Name] = append(b.res.Items[items.Folder.Name], items.Item{
+		ID:      id,
+		Type:    items.Folder.Name,
+		Name:    name,
+		Values:  map[string]any{},
+		Updated: b.now,
+	})
+	return id
+}
+
+// add - проверка и добавление записи типа t; entry - название записи
+// в исходном файле для отчета о пропуске.
+func (b *builder) add(entry string, t *items.Type, it items.Item) {
+	it.Name = truncate(strings.TrimSpace(it.Name), items.MaxNameLen)
+	if it.Name == "" {
+		b.skip(entry, "entry has no name")
+		return
+	}
+	it.ID = uuid.NewString()
+	it.Type = t.Name
+	it.Updated = b.now
+	if err := t.Check(&it); err != nil {
+		b.skip(entry, "%v", err)
+		return
+	}
+	b.res.Items[t.Name] = append(b.res.Items[t.Name], it)
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// urlField - произвольное поле-ссылка. Значения, которые не являются
+// абсолютными URL (например, "example.com"), сохраняются как текст.
+func urlField(name, value string) items.CustomField {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return items.CustomField{Name: name, Type: items.CustomFieldText, Value: value}
+	}
+	return items.CustomField{Name: name, Type: items.CustomFieldURL, Value: value}
+}
+
+// cardDate - срок действия карты в формате MM/YY. Год - две или четыре цифры.
+func cardDate(month, year string) (string, error) {
+	m, err := strconv.Atoi(strings.TrimSpace(month))
+	if err != nil || m < 1 || m > 12 {
+		return "", fmt.Errorf("invalid expiration month %q", month)
+	}
+	year = strings.TrimSpace(year)
+	if _, err := strconv.Atoi(year); err != nil || (len(year) != 2 && len(year) != 4) {
+		return "", fmt.Errorf("invalid expiration year %q", year)
+	}
+	return fmt.Sprintf("%02d/%s", m, year[len(year)-2:]), nil
+}
+
+// splitTags - теги, разделенные запятыми или точками с запятой.
+func splitTags(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' })
+}
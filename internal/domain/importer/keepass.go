@@ -0,0 +1,278 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+)
+
+const (
+	// maxAttachmentSize - предельный размер распакованного вложения KeePass.
+	maxAttachmentSize = 64 << 20
+	// maxAttachmentsTotal - предельный общий размер вложений одного импорта.
+	// Объект, на который ссылаются несколько записей, учитывается для каждой.
+	maxAttachmentsTotal = 256 << 20
+)
+
+// Стандартные поля записи KeePass.
+const (
+	kpTitle    = "Title"
+	kpUserName = "UserName"
+	kpPassword = "Password"
+	kpURL      = "URL"
+	kpNotes    = "Notes"
+)
+
+type kpFile struct {
+	Meta struct {
+		RecycleBinUUID string     `xml:"RecycleBinUUID"`
+		Binaries       []kpBinary `xml:"Binaries>Binary"`
+	} `xml:"Meta"`
+	Root struct {
+		Groups []kpGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+type kpBinary struct {
+	ID         string `xml:"ID,attr"`
+	Compressed bool   `xml:"Compressed,attr"`
+	Data       string `xml:",chardata"`
+}
+
+type kpGroup struct {
+	UUID    string    `xml:"UUID"`
+	Name    string    `xml:"Name"`
+	Entries []kpEntry `xml:"Entry"`
+	Groups  []kpGroup `xml:"Group"`
+}
+
+type kpEntry struct {
+	Tags    string `xml:"Tags"`
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value struct {
+			Protected       bool   `xml:"Protected,attr"`
+			ProtectInMemory bool   `xml:"ProtectInMemory,attr"`
+			Text            string `xml:",chardata"`
+		} `xml:"Value"`
+	} `xml:"String"`
+	Binaries []struct {
+		Key   string `xml:"Key"`
+		Value struct {
+			Ref string `xml:"Ref,attr"`
+		} `xml:"Value"`
+	} `xml:"Binary"`
+}
+
+// KeePass - разбор экспорта KeePass 2 XML. Группы переносятся в папки
+// (вложенные - с путем через "/"), записи с логином или паролем - в логины,
+// остальные записи с заметками - в тексты, вложения - в бинарные записи.
+// Записи корзины и история изменений не переносятся.
+func KeePass(data []byte) (Result, error) {
+	var file kpFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	if len(file.Root.Groups) == 0 {
+		return Result{}, fmt.Errorf("%w: no root group", ErrInvalidFile)
+	}
+	atts := &kpAttachments{
+		binaries: make(map[string]kpBinary, len(file.Meta.Binaries)),
+		decoded:  make(map[string][]byte),
+		failed:   make(map[string]error),
+	}
+	for _, bin := range file.Meta.Binaries {
+		atts.binaries[bin.ID] = bin
+	}
+
+	b := newBuilder()
+	var walk func(g kpGroup, path string) error
+	walk = func(g kpGroup, path string) error {
+		if file.Meta.RecycleBinUUID != "" && g.UUID == file.Meta.RecycleBinUUID {
+			for _, e := range g.Entries {
+				b.skip(entryName(e, path), "entry is in the recycle bin")
+			}
+			return nil
+		}
+		for _, e := range g.Entries {
+			if err := keePassEntry(b, e, path, atts); err != nil {
+				return err
+			}
+		}
+		for _, sub := range g.Groups {
+			subPath := sub.Name
+			if path != "" {
+				subPath = path + "/" + sub.Name
+			}
+			if err := walk(sub, subPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// Корневая группа - сама база, ее имя в путь папок не входит.
+	for _, root := range file.Root.Groups {
+		if err := walk(root, ""); err != nil {
+			return Result{}, err
+		}
+	}
+	return b.res, nil
+}
+
+// kpAttachments - двоичные объекты базы. Каждый объект распаковывается
+// не более одного раза, общий размер вложений ограничен maxAttachmentsTotal.
+type kpAttachments struct {
+	binaries map[string]kpBinary
+	decoded  map[string][]byte
+	failed   map[string]error
+	total    int
+}
+
+// data - содержимое объекта ref для очередного вложения. Превышение общего
+// предела возвращается как ErrImportTooLarge и прерывает импорт, остальные
+// ошибки относятся только к вложению.
+func (a *kpAttachments) data(ref string) ([]byte, error) {
+	if err, ok := a.failed[ref]; ok {
+		return nil, err
+	}
+	data, ok := a.decoded[ref]
+	if !ok {
+		var err error
+		data, err = a.binaries[ref].decode(min(maxAttachmentSize, maxAttachmentsTotal-a.total))
+		if errors.Is(err, ErrImportTooLarge) {
+			return nil, err
+		}
+		if err != nil {
+			a.failed[ref] = err
+			return nil, err
+		}
+		a.decoded[ref] = data
+	}
+	if a.total+len(data) > maxAttachmentsTotal {
+		return nil, fmt.Errorf("%w: attachments exceed %d bytes", ErrImportTooLarge, maxAttachmentsTotal)
+	}
+	a.total += len(data)
+	return data, nil
+}
+
+func keePassEntry(b *builder, e kpEntry, path string, atts *kpAttachments) error {
+	entry := entryName(e, path)
+	values := make(map[string]string, len(e.Strings))
+	it := items.Item{
+		FolderID: b.folder(path),
+		Tags:     splitTags(e.Tags),
+		Values:   make(map[string]any),
+	}
+	for _, s := range e.Strings {
+		if s.Value.Protected {
+			b.skip(entry, "protected values are encrypted; export the database as KeePass XML (2.x)")
+			return nil
+		}
+		switch s.Key {
+		case kpTitle, kpUserName, kpPassword, kpNotes:
+			values[s.Key] = s.Value.Text
+		case kpURL:
+			if s.Value.Text != "" {
+				it.CustomFields = append(it.CustomFields, urlField("url", s.Value.Text))
+			}
+		default:
+			typ := items.CustomFieldText
+			if s.Value.ProtectInMemory {
+				typ = items.CustomFieldHidden
+			}
+			it.CustomFields = append(it.CustomFields, items.CustomField{Name: s.Key, Type: typ, Value: s.Value.Text})
+		}
+	}
+	it.Name = values[kpTitle]
+
+	imported := false
+	switch {
+	case values[kpUserName] != "" || values[kpPassword] != "":
+		it.Values["login"] = values[kpUserName]
+		it.Values["password"] = values[kpPassword]
+		if values[kpNotes] != "" {
+			it.CustomFields = append(it.CustomFields, items.CustomField{Name: "notes", Type: items.CustomFieldText, Value: values[kpNotes]})
+		}
+		b.add(entry, items.Login, it)
+		imported = true
+	case values[kpNotes] != "":
+		it.Values["data"] = values[kpNotes]
+		b.add(entry, items.Text, it)
+		imported = true
+	}
+
+	for _, att := range e.Binaries {
+		attEntry := entry + "/" + att.Key
+		if _, ok := atts.binaries[att.Value.Ref]; !ok {
+			b.skip(attEntry, "attachment %q refers to missing binary %q", att.Key, att.Value.Ref)
+			continue
+		}
+		data, err := atts.data(att.Value.Ref)
+		if errors.Is(err, ErrImportTooLarge) {
+			return err
+		}
+		if err != nil {
+			b.skip(attEntry, "attachment %q: %v", att.Key, err)
+			continue
+		}
+		b.add(attEntry, items.Binary, items.Item{
+			Name:     strings.TrimSpace(it.Name + " " + att.Key),
+			FolderID: it.FolderID,
+			Tags:     it.Tags,
+			Values:   map[string]any{"data": data},
+		})
+		imported = true
+	}
+	if !imported {
+		b.skip(entry, "entry has no login, password, notes or attachments")
+	}
+	return nil
+}
+
+func entryName(e kpEntry, path string) string {
+	title := ""
+	for _, s := range e.Strings {
+		if s.Key == kpTitle {
+			title = s.Value.Text
+		}
+	}
+	if path == "" {
+		return title
+	}
+	return path + "/" + title
+}
+
+// decode - содержимое объекта не больше limit байт. Если limit меньше
+// maxAttachmentSize, он задан общим пределом импорта.
+func (bin kpBinary) decode(limit int) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(bin.Data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	if bin.Compressed {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		data, err = io.ReadAll(io.LimitReader(zr, int64(limit)+1))
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case len(data) <= limit:
+		return data, nil
+	case limit < maxAttachmentSize:
+		return nil, fmt.Errorf("%w: attachments exceed %d bytes", ErrImportTooLarge, maxAttachmentsTotal)
+	default:
+		return nil, fmt.Errorf("larger than %d bytes", maxAttachmentSize)
+	}
+}
@@ -0,0 +1,204 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+)
+
+// kpXML - экспорт KeePass с двоичными объектами binaries и записями entries
+// в корневой группе.
+func kpXML(binaries, entries string) []byte {
+	return []byte(`<?xml version="1.0" encoding="utf-8"?>
+<KeePassFile>
+	<Meta>
+		<RecycleBinUUID>bin</RecycleBinUUID>
+		<Binaries>` + binaries + `</Binaries>
+	</Meta>
+	<Root><Group><UUID>root</UUID><Name>Database</Name>` + entries + `</Group></Root>
+</KeePassFile>`)
+}
+
+func kpString(key, value string) string {
+	return fmt.Sprintf("<String><Key>%s</Key><Value>%s</Value></String>", key, value)
+}
+
+func kpAttachment(key, ref string) string {
+	return fmt.Sprintf(`<Binary><Key>%s</Key><Value Ref="%s"/></Binary>`, key, ref)
+}
+
+// gzipZeros - base64 сжатых gzip n нулевых байт.
+func gzipZeros(t testing.TB, n int) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	chunk := make([]byte, 1<<20)
+	for n > 0 {
+		c := min(n, len(chunk))
+		if _, err := zw.Write(chunk[:c]); err != nil {
+			t.Fatal(err)
+		}
+		n -= c
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// counts - число записей каждого типа.
+func counts(res Result) map[string]int {
+	c := make(map[string]int)
+	for name, list := range res.Items {
+		c[name] = len(list)
+	}
+	return c
+}
+
+func skippedEntries(res Result) []string {
+	entries := make([]string, 0, len(res.Skipped))
+	for _, s := range res.Skipped {
+		entries = append(entries, s.Entry)
+	}
+	return entries
+}
+
+func TestKeePass(t *testing.T) {
+	hello := base64.StdEncoding.EncodeToString([]byte("hello"))
+	tests := []struct {
+		name     string
+		data     []byte
+		counts   map[string]int
+		skipped  []string
+		wantErr  error
+		checkRes func(t *testing.T, res Result)
+	}{
+		{
+			name: "login note and attachment",
+			data: kpXML(`<Binary ID="0">`+hello+`</Binary>`,
+				`<Entry>`+kpString("Title", "mail")+kpString("UserName", "user")+kpString("Password", "secret")+
+					kpString("URL", "https://mail.example.com")+kpString("Notes", "note")+`</Entry>`+
+					`<Entry>`+kpString("Title", "memo")+kpString("Notes", "text")+kpAttachment("a.txt", "0")+`</Entry>`+
+					`<Group><UUID>g</UUID><Name>Work</Name><Entry>`+kpString("Title", "vpn")+kpString("Password", "p")+`</Entry></Group>`),
+			counts: map[string]int{"login": 2, "text": 1, "binary": 1, "folder": 1},
+			checkRes: func(t *testing.T, res Result) {
+				login := res.Items["login"][0]
+				if login.String("login") != "user" || login.String("password") != "secret" {
+					t.Errorf("login values = %v", login.Values)
+				}
+				if len(login.CustomFields) != 2 || login.CustomFields[0].Type != items.CustomFieldURL {
+					t.Errorf("login custom fields = %v", login.CustomFields)
+				}
+				if got := string(res.Items["binary"][0].Bytes("data")); got != "hello" {
+					t.Errorf("attachment data = %q", got)
+				}
+				if res.Items["login"][1].FolderID != res.Items["folder"][0].ID {
+					t.Error("entry of nested group is not in its folder")
+				}
+			},
+		},
+		{
+			name: "skipped entries",
+			data: kpXML("",
+				`<Entry>`+kpString("Title", "empty")+`</Entry>`+
+					`<Entry><String><Key>Title</Key><Value>locked</Value></String>`+
+					`<String><Key>Password</Key><Value Protected="True">c2VjcmV0</Value></String></Entry>`+
+					`<Entry>`+kpString("Title", "orphan")+kpAttachment("a", "7")+`</Entry>`+
+					`<Entry>`+kpString("UserName", "nameless")+`</Entry>`+
+					`<Group><UUID>bin</UUID><Name>Recycle Bin</Name><Entry>`+kpString("Title", "old")+kpString("Password", "p")+`</Entry></Group>`),
+			counts:  map[string]int{},
+			skipped: []string{"empty", "locked", "orphan/a", "orphan", "", "Recycle Bin/old"},
+		},
+		{
+			name:    "compressed attachment over limit",
+			data:    kpXML(`<Binary ID="0" Compressed="True">`+gzipZeros(t, maxAttachmentSize+1)+`</Binary>`, `<Entry>`+kpString("Title", "big")+kpAttachment("a", "0")+`</Entry>`),
+			counts:  map[string]int{},
+			skipped: []string{"big/a", "big"},
+		},
+		{
+			name:    "invalid xml",
+			data:    []byte("<KeePassFile>"),
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:    "no root group",
+			data:    []byte("<KeePassFile><Root></Root></KeePassFile>"),
+			wantErr: ErrInvalidFile,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := KeePass(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := counts(res); fmt.Sprint(got) != fmt.Sprint(tt.counts) {
+				t.Errorf("items = %v, want %v", got, tt.counts)
+			}
+			if got := skippedEntries(res); strings.Join(got, "|") != strings.Join(tt.skipped, "|") {
+				t.Errorf("skipped = %q, want %q (%v)", got, tt.skipped, res.Skipped)
+			}
+			if tt.checkRes != nil {
+				tt.checkRes(t, res)
+			}
+		})
+	}
+}
+
+// TestKeePassAttachmentsTotal - объект, на который ссылается много записей,
+// распаковывается один раз, но учитывается в общем пределе для каждой.
+func TestKeePassAttachmentsTotal(t *testing.T) {
+	const size = 60 << 20
+	bin := `<Binary ID="0" Compressed="True">` + gzipZeros(t, size) + `</Binary>`
+	entries := func(n int) string {
+		var sb strings.Builder
+		for i := 0; i < n; i++ {
+			sb.WriteString(`<Entry>` + kpString("Title", fmt.Sprintf("e%d", i)) + kpAttachment("a", "0") + `</Entry>`)
+		}
+		return sb.String()
+	}
+
+	within := maxAttachmentsTotal / size
+	res, err := KeePass(kpXML(bin, entries(within)))
+	if err != nil {
+		t.Fatalf("%d attachments: %v", within, err)
+	}
+	list := res.Items["binary"]
+	if len(list) != within {
+		t.Fatalf("imported %d attachments, want %d", len(list), within)
+	}
+	first := list[0].Bytes("data")
+	for _, it := range list[1:] {
+		if &it.Bytes("data")[0] != &first[0] {
+			t.Fatal("shared binary is decoded more than once")
+		}
+	}
+
+	if _, err := KeePass(kpXML(bin, entries(within+1))); !errors.Is(err, ErrImportTooLarge) {
+		t.Errorf("%d attachments: error = %v, want %v", within+1, err, ErrImportTooLarge)
+	}
+}
+
+// TestKeePassDistinctBinariesTotal - разные объекты не распаковываются
+// сверх остатка общего предела.
+func TestKeePassDistinctBinariesTotal(t *testing.T) {
+	const size = 60 << 20
+	zeros := gzipZeros(t, size)
+	var bins, entries strings.Builder
+	for i := 0; i <= maxAttachmentsTotal/size; i++ {
+		fmt.Fprintf(&bins, `<Binary ID="%d" Compressed="True">%s</Binary>`, i, zeros)
+		entries.WriteString(`<Entry>` + kpString("Title", fmt.Sprintf("e%d", i)) + kpAttachment("a", fmt.Sprint(i)) + `</Entry>`)
+	}
+	if _, err := KeePass(kpXML(bins.String(), entries.String())); !errors.Is(err, ErrImportTooLarge) {
+		t.Errorf("error = %v, want %v", err, ErrImportTooLarge)
+	}
+}
@@ -0,0 +1,103 @@
+package items
+
+import (
+	gophkeeperv1 "github.com/Dorrrke/goph-keeper-proto/gen/go/gophkeeper"
+)
+
+// Встроенные типы записей.
+var (
+	Folder = &Type{
+		Name:      "folder",
+		Table:     "folders",
+		ListField: "folders",
+		ItemField: "folder",
+		Message:   &gophkeeperv1.SyncFolder{},
+	}
+	Card = &Type{
+		Name:      "card",
+		Table:     "cards",
+		ListField: "cards",
+		ItemField: "card",
+		Meta:      true,
+		Order:     1,
+		Message:   &gophkeeperv1.SyncCard{},
+		Fields: []Field{
+			{Name: "number", Column: "number", Secret: true, MaxLen: 19},
+			{Name: "date", Column: "date", MaxLen: 5},
+			{Name: "cvv", Column: "cvv", Secret: true, MaxLen: 4},
+			{Name: "cardholder", Column: "cardholder", MaxLen: 100},
+			{Name: "billing_address", Column: "billing_address", Secret: true},
+			{Name: "pin", Column: "pin", Secret: true, MaxLen: 12},
+			// brand определяется сервером по номеру карты, значение клиента
+			// заменяется.
+			{Name: "brand", Column: "brand", MaxLen: 20},
+		},
+		Normalize: normalizeCard,
+		Validate:  validateCard,
+	}
+	// Encrypted - запись, зашифрованная клиентом. Открыто хранятся только id,
+	// тип содержимого (kind), ревизия и признак удаления; name - непрозрачная
+	// метка клиента и может быть пустой.
+	Encrypted = &Type{
+		Name:      "encrypted",
+		Table:     "encrypted_items",
+		ListField: "encrypted",
+		ItemField: "encrypted",
+		Opaque:    true,
+		Order:     1,
+		Message:   &gophkeeperv1.SyncEncrypted{},
+		Fields: []Field{
+			{Name: "kind", Column: "kind", MaxLen: 32},
+			{Name: "ciphertext", Column: "ciphertext", Kind: KindBytes, Secret: true},
+			// revision увеличивается базой данных при каждом изменении записи.
+			{Name: "revision", Column: "revision", Kind: KindInt64, ReadOnly: true},
+		},
+		Validate: validateEncrypted,
+	}
+	Login = &Type{
+		Name:      "login",
+		Table:     "logins",
+		ListField: "auth",
+		ItemField: "auth",
+		Meta:      true,
+		Order:     1,
+		Message:   &gophkeeperv1.SyncAuth{},
+		Fields: []Field{
+			{Name: "login", Column: "login", MaxLen: 255},
+			{Name: "password", Column: "password", Secret: true},
+		},
+	}
+	Text = &Type{
+		Name:      "text",
+		Table:     "text_data",
+		ListField: "texts",
+		ItemField: "text",
+		Meta:      true,
+		Order:     1,
+		Message:   &gophkeeperv1.SyncText{},
+		Fields: []Field{
+			{Name: "data", Column: "data", Secret: true},
+		},
+	}
+	Binary = &Type{
+		Name:      "binary",
+		Table:     "binares_data",
+		ListField: "bins",
+		ItemField: "bin",
+		Meta:      true,
+		Order:     1,
+		Message:   &gophkeeperv1.SyncBinData{},
+		Fields: []Field{
+			{Name: "data", Column: "data", Kind: KindBytes, Secret: true},
+		},
+	}
+)
+
+func init() {
+	Register(Folder)
+	Register(Text)
+	Register(Login)
+	Register(Binary)
+	Register(Card)
+	Register(Encrypted)
+}
@@ -0,0 +1,141 @@
+package items
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Платежные системы, определяемые по номеру карты.
+const (
+	BrandVisa       = "visa"
+	BrandMastercard = "mastercard"
+	BrandAmex       = "amex"
+	BrandDiscover   = "discover"
+	BrandJCB        = "jcb"
+	BrandDiners     = "diners"
+	BrandUnionPay   = "unionpay"
+	BrandMaestro    = "maestro"
+	BrandMir        = "mir"
+)
+
+const (
+	minCardNumberLen = 12
+	maxCardNumberLen = 19
+)
+
+// brandRanges - диапазоны префиксов номеров (IIN). Проверяются по порядку,
+// поэтому более узкие диапазоны стоят раньше общих.
+var brandRanges = []struct {
+	brand    string
+	from, to int
+	digits   int
+}{
+	{BrandMir, 2200, 2204, 4},
+	{BrandMastercard, 2221, 2720, 4},
+	{BrandMastercard, 51, 55, 2},
+	{BrandAmex, 34, 34, 2},
+	{BrandAmex, 37, 37, 2},
+	{BrandDiners, 300, 305, 3},
+	{BrandDiners, 36, 36, 2},
+	{BrandDiners, 38, 39, 2},
+	{BrandJCB, 3528, 3589, 4},
+	{BrandDiscover, 6011, 6011, 4},
+	{BrandDiscover, 644, 649, 3},
+	{BrandDiscover, 65, 65, 2},
+	{BrandUnionPay, 62, 62, 2},
+	{BrandMaestro, 50, 50, 2},
+	{BrandMaestro, 56, 58, 2},
+	{BrandMaestro, 6, 6, 1},
+	{BrandVisa, 4, 4, 1},
+}
+
+// CardBrand - платежная система по номеру карты, пустая строка, если не определена.
+func CardBrand(number string) string {
+	for _, r := range brandRanges {
+		if len(number) < r.digits {
+			continue
+		}
+		prefix, err := strconv.Atoi(number[:r.digits])
+		if err != nil {
+			return ""
+		}
+		if prefix >= r.from && prefix <= r.to {
+			return r.brand
+		}
+	}
+	return ""
+}
+
+// luhnValid - проверка контрольной цифры номера по алгоритму Луна.
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeCard - удаление пробелов и дефисов из номера карты и определение
+// платежной системы по номеру.
+func normalizeCard(it *Item) {
+	number := strings.NewReplacer(" ", "", "-", "").Replace(it.String("number"))
+	it.Values["number"] = number
+	it.Values["brand"] = CardBrand(number)
+}
+
+// validateCard - проверка карты: номер из 12-19 цифр с верной контрольной
+// цифрой, срок действия MM/YY, CVV из 3-4 цифр и PIN из 4-12 цифр, если
+// заданы. Номер должен быть нормализован normalizeCard.
+func validateCard(it *Item) []FieldViolation {
+	var violations []FieldViolation
+	add := func(field, format string, args ...any) {
+		violations = append(violations, FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+	}
+
+	number := it.String("number")
+	switch {
+	case !isDigits(number):
+		add("number", "must contain only digits")
+	case len(number) < minCardNumberLen || len(number) > maxCardNumberLen:
+		add("number", "must be %d to %d digits long", minCardNumberLen, maxCardNumberLen)
+	case !luhnValid(number):
+		add("number", "check digit is invalid")
+	}
+
+	date := it.String("date")
+	month, year, ok := strings.Cut(date, "/")
+	if m, err := strconv.Atoi(month); !ok || len(month) != 2 || !isDigits(month) || len(year) != 2 ||
+		!isDigits(year) || err != nil || m < 1 || m > 12 {
+		add("date", "must be MM/YY")
+	}
+
+	if cvv := it.String("cvv"); cvv != "" && (!isDigits(cvv) || len(cvv) < 3 || len(cvv) > 4) {
+		add("cvv", "must be 3 or 4 digits")
+	}
+	if pin := it.String("pin"); pin != "" && (!isDigits(pin) || len(pin) < 4 || len(pin) > 12) {
+		add("pin", "must be 4 to 12 digits")
+	}
+	return violations
+}
@@ -0,0 +1,132 @@
+package items
+
+import (
+	"testing"
+)
+
+func TestLuhnValid(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{"4111111111111111", true},
+		{"4111111111111112", false},
+		{"5555555555554444", true},
+		{"378282246310005", true},
+		{"79927398713", true},
+		{"79927398710", false},
+		{"0", true},
+	}
+	for _, tt := range tests {
+		if got := luhnValid(tt.number); got != tt.want {
+			t.Errorf("luhnValid(%q) = %v, want %v", tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestCardBrand(t *testing.T) {
+	tests := []struct {
+		number string
+		want   string
+	}{
+		{"4111111111111111", BrandVisa},
+		{"5555555555554444", BrandMastercard},
+		{"2221000000000009", BrandMastercard},
+		{"2200000000000004", BrandMir},
+		{"378282246310005", BrandAmex},
+		{"341111111111111", BrandAmex},
+		{"30569309025904", BrandDiners},
+		{"36000000000008", BrandDiners},
+		{"3530111333300000", BrandJCB},
+		{"6011111111111117", BrandDiscover},
+		{"6440000000000000", BrandDiscover},
+		{"6200000000000005", BrandUnionPay},
+		{"5018000000000009", BrandMaestro},
+		{"6759649826438453", BrandMaestro},
+		{"1234567890123", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := CardBrand(tt.number); got != tt.want {
+			t.Errorf("CardBrand(%q) = %q, want %q", tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestValidateCard(t *testing.T) {
+	valid := func() map[string]any {
+		return map[string]any{
+			"number": "4111 1111-1111 1111",
+			"date":   "12/30",
+			"cvv":    "123",
+			"pin":    "",
+		}
+	}
+	tests := []struct {
+		name   string
+		change map[string]any
+		fields []string
+	}{
+		{"valid", nil, nil},
+		{"letters in number", map[string]any{"number": "4111a11111111111"}, []string{"number"}},
+		{"short number", map[string]any{"number": "42424242"}, []string{"number"}},
+		{"long number", map[string]any{"number": "42424242424242424242"}, []string{"number"}},
+		{"bad check digit", map[string]any{"number": "4111111111111112"}, []string{"number"}},
+		{"month 00", map[string]any{"date": "00/30"}, []string{"date"}},
+		{"month 13", map[string]any{"date": "13/30"}, []string{"date"}},
+		{"single digit month", map[string]any{"date": "1/30"}, []string{"date"}},
+		{"signed month", map[string]any{"date": "+1/25"}, []string{"date"}},
+		{"negative month", map[string]any{"date": "-0/25"}, []string{"date"}},
+		{"signed year", map[string]any{"date": "12/+5"}, []string{"date"}},
+		{"no separator", map[string]any{"date": "1230"}, []string{"date"}},
+		{"four digit year", map[string]any{"date": "12/2030"}, []string{"date"}},
+		{"short cvv", map[string]any{"cvv": "12"}, []string{"cvv"}},
+		{"four digit cvv", map[string]any{"cvv": "1234"}, nil},
+		{"letters in cvv", map[string]any{"cvv": "12a"}, []string{"cvv"}},
+		{"short pin", map[string]any{"pin": "123"}, []string{"pin"}},
+		{"long pin", map[string]any{"pin": "1234567890123"}, []string{"pin"}},
+		{"valid pin", map[string]any{"pin": "1234"}, nil},
+		{"several errors", map[string]any{"number": "x", "date": "x", "cvv": "x"}, []string{"number", "date", "cvv"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := &Item{Values: valid()}
+			for k, v := range tt.change {
+				it.Values[k] = v
+			}
+			normalizeCard(it)
+			violations := validateCard(it)
+			if len(violations) != len(tt.fields) {
+				t.Fatalf("violations = %v, want fields %v", violations, tt.fields)
+			}
+			for i, v := range violations {
+				if v.Field != tt.fields[i] {
+					t.Errorf("violation %d field = %q, want %q", i, v.Field, tt.fields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeCard(t *testing.T) {
+	tests := []struct {
+		number     string
+		wantNumber string
+		wantBrand  string
+	}{
+		{"4111 1111-1111 1111", "4111111111111111", BrandVisa},
+		{"5555-5555-5555-4444", "5555555555554444", BrandMastercard},
+		{"1234", "1234", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		it := &Item{Values: map[string]any{"number": tt.number, "brand": "client brand"}}
+		normalizeCard(it)
+		if got := it.String("number"); got != tt.wantNumber {
+			t.Errorf("number %q: normalized = %q, want %q", tt.number, got, tt.wantNumber)
+		}
+		if got := it.String("brand"); got != tt.wantBrand {
+			t.Errorf("number %q: brand = %q, want %q", tt.number, got, tt.wantBrand)
+		}
+	}
+}
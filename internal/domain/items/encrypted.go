@@ -0,0 +1,15 @@
+package items
+
+// validateEncrypted - проверка записи, зашифрованной клиентом. Сервер видит
+// только тип содержимого и размер шифртекста.
+func validateEncrypted(it *Item) []FieldViolation {
+	var violations []FieldViolation
+	kind := it.String("kind")
+	if kind == "" {
+		violations = append(violations, FieldViolation{Field: "kind", Description: "is required"})
+	}
+	if len(it.Bytes("ciphertext")) == 0 {
+		violations = append(violations, FieldViolation{Field: "ciphertext", Description: "is required"})
+	}
+	return violations
+}
@@ -0,0 +1,193 @@
+// Package items - обобщенное описание записей хранилища и реестр их типов.
+//
+// Тип записи (карта, логин, текст, бинарные данные) описывается значением Type:
+// таблица, поля, proto-сообщение и проверка. Движок синхронизации, слой запросов
+// и преобразование proto-сообщений работают только с этим описанием, поэтому
+// новый тип добавляется одним вызовом Register.
+package items
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// MaxNameLen - длина колонки name таблиц записей.
+const MaxNameLen = 100
+
+// Kind - тип значения поля.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindBytes
+	KindInt64
+)
+
+// Field - поле, специфичное для типа записи.
+type Field struct {
+	// Name - имя поля в proto-сообщении.
+	Name string
+	// Column - колонка таблицы.
+	Column string
+	Kind   Kind
+	// SQLType - тип колонки, если он отличается от представления поля;
+	// значение читается как text и приводится к SQLType при записи.
+	SQLType string
+	// Secret - поле не возвращается в метаданных записи.
+	Secret bool
+	// ReadOnly - значение назначается базой данных; присланное клиентом
+	// значение игнорируется.
+	ReadOnly bool
+	// MaxLen - длина колонки строкового поля в символах; 0 - без ограничения.
+	MaxLen int
+}
+
+// Type - описание типа записи.
+type Type struct {
+	// Name - имя типа.
+	Name string
+	// Table - таблица с записями этого типа.
+	Table string
+	// ListField - имя repeated-поля в SyncDBRequest и SyncDBResponse.
+	ListField string
+	// ItemField - имя поля oneof в сообщении Item.
+	ItemField string
+	// Message - пустое proto-сообщение записи.
+	Message proto.Message
+	Fields  []Field
+	// Meta - записи типа содержат папку, теги и произвольные поля.
+	Meta bool
+	// Order - порядок синхронизации: типы с меньшим значением синхронизируются
+	// раньше (например, папки до записей, которые на них ссылаются).
+	Order int
+	// Opaque - значения зашифрованы клиентом. id таких записей назначает
+	// клиент (шифртекст может быть связан с ним), записи не сопоставляются
+	// по имени и не создаются импортом из внешних форматов.
+	Opaque bool
+	// Normalize - нормализация значений и вычисление полей, которые назначает
+	// сервер (например, платежной системы карты по номеру), может быть nil.
+	// Вызывается для всех записей, в том числе удаленных, до проверок.
+	Normalize func(*Item)
+	// Validate - дополнительная проверка записи, может быть nil.
+	Validate func(*Item) []FieldViolation
+}
+
+// Item - запись хранилища любого типа.
+type Item struct {
+	// ID - серверный идентификатор (UUID), пустой у новых записей клиента.
+	ID     string
+	Type   string
+	UserID int
+	Name   string
+	// Values - значения полей типа по Field.Name: string или []byte по Kind.
+	Values  map[string]any
+	Deleted bool
+	Updated time.Time
+
+	// FolderID, Tags и CustomFields заполняются только для типов с Meta.
+	FolderID     string
+	Tags         []string
+	CustomFields []CustomField
+}
+
+// String - строковое значение поля.
+func (it Item) String(field string) string {
+	v, _ := it.Values[field].(string)
+	return v
+}
+
+// Bytes - бинарное значение поля.
+func (it Item) Bytes(field string) []byte {
+	v, _ := it.Values[field].([]byte)
+	return v
+}
+
+// Int64 - целочисленное значение поля.
+func (it Item) Int64(field string) int64 {
+	v, _ := it.Values[field].(int64)
+	return v
+}
+
+// Check - нормализация и проверка записи типа t. Ошибки возвращаются
+// по полям в *ValidationError.
+func (t *Type) Check(it *Item) error {
+	var violations []FieldViolation
+	if t.Meta {
+		it.Tags = normalizeTags(it.Tags)
+	}
+	if t.Normalize != nil {
+		t.Normalize(it)
+	}
+	if t.Opaque && it.ID == "" {
+		violations = append(violations, FieldViolation{Field: protoID, Description: "is required for client-encrypted items"})
+	}
+	// Записи без шифрования сопоставляются по имени, поэтому оно обязательно.
+	switch {
+	case !t.Opaque && it.Name == "":
+		violations = append(violations, FieldViolation{Field: protoName, Description: "is required"})
+	case utf8.RuneCountInString(it.Name) > MaxNameLen:
+		violations = append(violations, FieldViolation{Field: protoName,
+			Description: fmt.Sprintf("must be at most %d characters", MaxNameLen)})
+	}
+	for _, f := range t.Fields {
+		if f.MaxLen > 0 && !f.ReadOnly && utf8.RuneCountInString(it.String(f.Name)) > f.MaxLen {
+			violations = append(violations, FieldViolation{Field: f.Name,
+				Description: fmt.Sprintf("must be at most %d characters", f.MaxLen)})
+		}
+	}
+	// Удаленная запись передает только факт удаления, ее значения не проверяются.
+	if !it.Deleted && t.Meta {
+		violations = append(violations, validateMeta(*it)...)
+	}
+	if !it.Deleted && t.Validate != nil {
+		violations = append(violations, t.Validate(it)...)
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Type: t.Name, Name: it.Name, Violations: violations}
+	}
+	return nil
+}
+
+// Set - записи, сгруппированные по имени типа.
+type Set map[string][]Item
+
+// Len - общее число записей.
+func (s Set) Len() int {
+	n := 0
+	for _, list := range s {
+		n += len(list)
+	}
+	return n
+}
+
+var (
+	registry []*Type
+	byName   = make(map[string]*Type)
+)
+
+// Register - регистрация типа записи. Вызывается при инициализации пакета,
+// ошибки описания приводят к панике.
+func Register(t *Type) {
+	if _, ok := byName[t.Name]; ok {
+		panic(fmt.Sprintf("items: type %q registered twice", t.Name))
+	}
+	if err := checkProto(t); err != nil {
+		panic(fmt.Sprintf("items: type %q: %v", t.Name, err))
+	}
+	registry = append(registry, t)
+	byName[t.Name] = t
+}
+
+// Types - зарегистрированные типы в порядке регистрации.
+func Types() []*Type {
+	return registry
+}
+
+// Lookup - тип по имени.
+func Lookup(name string) (*Type, bool) {
+	t, ok := byName[name]
+	return t, ok
+}
@@ -0,0 +1,85 @@
+package items
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckLimits(t *testing.T) {
+	card := func(deleted bool, values map[string]any) *Item {
+		it := &Item{Type: Card.Name, Name: "card", Deleted: deleted, Values: map[string]any{
+			"number": "4111111111111111",
+			"date":   "12/30",
+			"cvv":    "123",
+		}}
+		for k, v := range values {
+			it.Values[k] = v
+		}
+		return it
+	}
+	tests := []struct {
+		name   string
+		typ    *Type
+		it     *Item
+		fields []string
+	}{
+		{"valid card", Card, card(false, nil), nil},
+		{"name too long", Card, &Item{Name: strings.Repeat("n", MaxNameLen+1), Values: card(false, nil).Values}, []string{"name"}},
+		{"name at limit", Card, &Item{Name: strings.Repeat("я", MaxNameLen), Values: card(false, nil).Values}, nil},
+		{"name required", Card, &Item{Values: card(false, nil).Values}, []string{"name"}},
+		{"cardholder too long", Card, card(false, map[string]any{"cardholder": strings.Repeat("a", 101)}), []string{"cardholder"}},
+		{"spaced number fits", Card, card(false, map[string]any{"number": "4111 1111 1111 1111"}), nil},
+		{"deleted long number", Card, card(true, map[string]any{"number": strings.Repeat("4", 20)}), []string{"number"}},
+		{"deleted long date", Card, card(true, map[string]any{"date": "12/2030"}), []string{"date"}},
+		{"deleted long cvv", Card, card(true, map[string]any{"cvv": "12345"}), []string{"cvv"}},
+		{"deleted long pin", Card, card(true, map[string]any{"pin": strings.Repeat("1", 13)}), []string{"pin"}},
+		{"deleted invalid card", Card, card(true, map[string]any{"number": "x", "date": "x"}), nil},
+		{"client brand ignored", Card, card(true, map[string]any{"brand": strings.Repeat("b", 50)}), nil},
+		{"login too long", Login, &Item{Name: "login", Values: map[string]any{"login": strings.Repeat("l", 256)}}, []string{"login"}},
+		{"opaque without id", Encrypted, &Item{Values: map[string]any{"kind": "note", "ciphertext": []byte{1}}}, []string{"id"}},
+		{"opaque kind too long", Encrypted, &Item{ID: "0d8f7c34-7d53-4c39-9a3f-7a4d9f1e2b60",
+			Values: map[string]any{"kind": strings.Repeat("k", 33), "ciphertext": []byte{1}}}, []string{"kind"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.typ.Check(tt.it)
+			var vErr *ValidationError
+			if err != nil && !errors.As(err, &vErr) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			var got []string
+			if vErr != nil {
+				for _, v := range vErr.Violations {
+					got = append(got, v.Field)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("violations = %v, want fields %v", err, tt.fields)
+			}
+		})
+	}
+}
+
+func TestCheckDerivesBrand(t *testing.T) {
+	tests := []struct {
+		name    string
+		deleted bool
+	}{
+		{"active", false},
+		{"deleted", true},
+	}
+	for _, tt := range tests {
+		it := &Item{Name: "card", Deleted: tt.deleted, Values: map[string]any{
+			"number": "5555 5555 5555 4444",
+			"date":   "12/30",
+			"brand":  "client",
+		}}
+		if err := Card.Check(it); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := it.String("brand"); got != BrandMastercard {
+			t.Errorf("%s: brand = %q, want %q", tt.name, got, BrandMastercard)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package items
+
+import "time"
+
+// Filter - условия выборки записей для списка и поиска.
+type Filter struct {
+	// Types - имена типов; пустой список - все типы.
+	Types         []*Type
+	NamePrefix    string
+	NameContains  string
+	Query         string
+	Tag           string
+	FolderID      string
+	ModifiedSince time.Time
+	WithDeleted   bool
+}
+
+// Cursor - позиция в списке, упорядоченном по имени, типу и id.
+type Cursor struct {
+	Name string `json:"n"`
+	Type string `json:"t"`
+	ID   string `json:"i"`
+}
+
+// Meta - метаданные записи без секретных полей.
+type Meta struct {
+	ID         string
+	Type       string
+	Name       string
+	FolderID   string
+	Tags       []string
+	Attributes map[string]string
+	Deleted    bool
+	Updated    time.Time
+}
+
+// Cursor - позиция записи в списке.
+func (m Meta) Cursor() Cursor {
+	return Cursor{Name: m.Name, Type: m.Type, ID: m.ID}
+}
@@ -0,0 +1,72 @@
+package items
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Типы произвольных полей.
+const (
+	CustomFieldText    = "text"
+	CustomFieldHidden  = "hidden"
+	CustomFieldBoolean = "boolean"
+	CustomFieldURL     = "url"
+)
+
+// CustomField - произвольное поле записи, хранится в jsonb.
+type CustomField struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// normalizeTags - теги без пробелов по краям, пустых значений и повторов.
+func normalizeTags(tags []string) []string {
+	res := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		res = append(res, tag)
+	}
+	return res
+}
+
+func validateMeta(it Item) []FieldViolation {
+	var violations []FieldViolation
+	if it.FolderID != "" {
+		if _, err := uuid.Parse(it.FolderID); err != nil {
+			violations = append(violations, FieldViolation{Field: protoFolderID, Description: "must be a UUID"})
+		}
+	}
+	for i, f := range it.CustomFields {
+		field := fmt.Sprintf("%s[%d]", protoFields, i)
+		if strings.TrimSpace(f.Name) == "" {
+			violations = append(violations, FieldViolation{Field: field + ".name", Description: "is required"})
+		}
+		switch f.Type {
+		case CustomFieldText, CustomFieldHidden:
+		case CustomFieldBoolean:
+			if f.Value != "true" && f.Value != "false" {
+				violations = append(violations, FieldViolation{Field: field + ".value", Description: `boolean value must be "true" or "false"`})
+			}
+		case CustomFieldURL:
+			if f.Value == "" {
+				continue
+			}
+			u, err := url.Parse(f.Value)
+			if err != nil || u.Scheme == "" || u.Host == "" {
+				violations = append(violations, FieldViolation{Field: field + ".value", Description: "must be an absolute URL"})
+			}
+		default:
+			violations = append(violations, FieldViolation{Field: field + ".type", Description: fmt.Sprintf("unknown type %q", f.Type)})
+		}
+	}
+	return violations
+}
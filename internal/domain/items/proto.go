@@ -0,0 +1,290 @@
+package items
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	gophkeeperv1 "github.com/Dorrrke/goph-keeper-proto/gen/go/gophkeeper"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Общие поля proto-сообщений записей.
+const (
+	protoID      = "id"
+	protoName    = "name"
+	protoDeleted = "deleted"
+	protoUpdated = "updated"
+
+	protoFolderID = "folder_id"
+	protoTags     = "tags"
+	protoFields   = "fields"
+)
+
+var customFieldTypes = map[gophkeeperv1.CustomFieldType]string{
+	gophkeeperv1.CustomFieldType_CUSTOM_FIELD_TEXT:    CustomFieldText,
+	gophkeeperv1.CustomFieldType_CUSTOM_FIELD_HIDDEN:  CustomFieldHidden,
+	gophkeeperv1.CustomFieldType_CUSTOM_FIELD_BOOLEAN: CustomFieldBoolean,
+	gophkeeperv1.CustomFieldType_CUSTOM_FIELD_URL:     CustomFieldURL,
+}
+
+func checkProto(t *Type) error {
+	if t.Message == nil {
+		return errors.New("message is required")
+	}
+	desc := t.Message.ProtoReflect().Descriptor()
+	for _, name := range []string{protoID, protoName, protoDeleted, protoUpdated} {
+		if desc.Fields().ByName(protoreflect.Name(name)) == nil {
+			return fmt.Errorf("message %s has no field %q", desc.FullName(), name)
+		}
+	}
+	itemFd := (&gophkeeperv1.Item{}).ProtoReflect().Descriptor().Fields().ByName(protoreflect.Name(t.ItemField))
+	if itemFd == nil || itemFd.Message() == nil || itemFd.Message().FullName() != desc.FullName() {
+		return fmt.Errorf("Item has no field %q of type %s", t.ItemField, desc.FullName())
+	}
+	if t.Meta {
+		for _, name := range []string{protoFolderID, protoTags, protoFields} {
+			if desc.Fields().ByName(protoreflect.Name(name)) == nil {
+				return fmt.Errorf("message %s has no field %q", desc.FullName(), name)
+			}
+		}
+	}
+	for _, f := range t.Fields {
+		fd := desc.Fields().ByName(protoreflect.Name(f.Name))
+		if fd == nil {
+			return fmt.Errorf("message %s has no field %q", desc.FullName(), f.Name)
+		}
+		want := protoreflect.StringKind
+		switch f.Kind {
+		case KindBytes:
+			want = protoreflect.BytesKind
+		case KindInt64:
+			want = protoreflect.Int64Kind
+		}
+		if fd.Kind() != want {
+			return fmt.Errorf("field %q has kind %s, want %s", f.Name, fd.Kind(), want)
+		}
+	}
+	return nil
+}
+
+// Count - число записей всех зарегистрированных типов в сообщении.
+func Count(msg proto.Message) int {
+	n := 0
+	m := msg.ProtoReflect()
+	for _, t := range registry {
+		if fd := m.Descriptor().Fields().ByName(protoreflect.Name(t.ListField)); fd != nil {
+			n += m.Get(fd).List().Len()
+		}
+	}
+	return n
+}
+
+// FromProto - разбор всех зарегистрированных списков записей из сообщения
+// (например, SyncDBRequest). Каждая запись проверяется; ошибки всех записей
+// возвращаются вместе через errors.Join.
+func FromProto(msg proto.Message, uID int) (Set, error) {
+	set := make(Set)
+	var errs []error
+	m := msg.ProtoReflect()
+	for _, t := range registry {
+		fd := m.Descriptor().Fields().ByName(protoreflect.Name(t.ListField))
+		if fd == nil {
+			continue
+		}
+		list := m.Get(fd).List()
+		for i := 0; i < list.Len(); i++ {
+			it, err := t.FromProto(list.Get(i).Message().Interface(), uID)
+			if err != nil {
+				var vErr *ValidationError
+				if errors.As(err, &vErr) {
+					vErr.Path = fmt.Sprintf("%s[%d]", t.ListField, i)
+				}
+				errs = append(errs, err)
+				continue
+			}
+			set[t.Name] = append(set[t.Name], it)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return set, nil
+}
+
+// ToProto - заполнение списков записей сообщения (например, SyncDBResponse).
+func (s Set) ToProto(msg proto.Message) {
+	m := msg.ProtoReflect()
+	for _, t := range registry {
+		fd := m.Descriptor().Fields().ByName(protoreflect.Name(t.ListField))
+		if fd == nil {
+			continue
+		}
+		list := m.Mutable(fd).List()
+		for _, it := range s[t.Name] {
+			list.Append(protoreflect.ValueOfMessage(t.ToProto(it).ProtoReflect()))
+		}
+	}
+}
+
+// FromProto - разбор одной записи типа t.
+func (t *Type) FromProto(msg proto.Message, uID int) (Item, error) {
+	m := msg.ProtoReflect()
+	fields := m.Descriptor().Fields()
+	var violations []FieldViolation
+	updated := m.Get(fields.ByName(protoUpdated)).String()
+	ts, err := time.Parse(time.RFC3339, updated)
+	if err != nil {
+		violations = append(violations, FieldViolation{Field: protoUpdated, Description: "must be an RFC 3339 time"})
+	}
+	id := m.Get(fields.ByName(protoID)).String()
+	if id != "" {
+		if _, err := uuid.Parse(id); err != nil {
+			violations = append(violations, FieldViolation{Field: protoID, Description: "must be a UUID"})
+		}
+	}
+	it := Item{
+		ID:      id,
+		Type:    t.Name,
+		UserID:  uID,
+		Name:    m.Get(fields.ByName(protoName)).String(),
+		Values:  make(map[string]any, len(t.Fields)),
+		Deleted: m.Get(fields.ByName(protoDeleted)).Bool(),
+		Updated: ts,
+	}
+	for _, f := range t.Fields {
+		if f.ReadOnly {
+			continue
+		}
+		v := m.Get(fields.ByName(protoreflect.Name(f.Name)))
+		switch f.Kind {
+		case KindBytes:
+			it.Values[f.Name] = v.Bytes()
+		case KindInt64:
+			it.Values[f.Name] = v.Int()
+		default:
+			it.Values[f.Name] = v.String()
+		}
+	}
+	if t.Meta {
+		metaFromProto(m, &it)
+	}
+	if err := t.Check(&it); err != nil {
+		var vErr *ValidationError
+		if !errors.As(err, &vErr) {
+			return Item{}, err
+		}
+		violations = append(violations, vErr.Violations...)
+	}
+	if len(violations) > 0 {
+		return Item{}, &ValidationError{Type: t.Name, Name: it.Name, Violations: violations}
+	}
+	return it, nil
+}
+
+// ToProto - proto-сообщение записи типа t.
+func (t *Type) ToProto(it Item) proto.Message {
+	m := t.Message.ProtoReflect().New()
+	fields := m.Descriptor().Fields()
+	m.Set(fields.ByName(protoID), protoreflect.ValueOfString(it.ID))
+	m.Set(fields.ByName(protoName), protoreflect.ValueOfString(it.Name))
+	m.Set(fields.ByName(protoDeleted), protoreflect.ValueOfBool(it.Deleted))
+	m.Set(fields.ByName(protoUpdated), protoreflect.ValueOfString(it.Updated.Format(time.RFC3339)))
+	for _, f := range t.Fields {
+		fd := fields.ByName(protoreflect.Name(f.Name))
+		switch f.Kind {
+		case KindBytes:
+			m.Set(fd, protoreflect.ValueOfBytes(it.Bytes(f.Name)))
+		case KindInt64:
+			m.Set(fd, protoreflect.ValueOfInt64(it.Int64(f.Name)))
+		default:
+			m.Set(fd, protoreflect.ValueOfString(it.String(f.Name)))
+		}
+	}
+	if t.Meta {
+		metaToProto(it, m)
+	}
+	return m.Interface()
+}
+
+// listOverhead - предел размера тега и длины записи в repeated-поле.
+const listOverhead = 16
+
+// Size - размер записи в списке proto-сообщения в байтах.
+func (t *Type) Size(it Item) int {
+	return proto.Size(t.ToProto(it)) + listOverhead
+}
+
+func metaFromProto(m protoreflect.Message, it *Item) {
+	fields := m.Descriptor().Fields()
+	it.FolderID = m.Get(fields.ByName(protoFolderID)).String()
+
+	tags := m.Get(fields.ByName(protoTags)).List()
+	it.Tags = make([]string, 0, tags.Len())
+	for i := 0; i < tags.Len(); i++ {
+		it.Tags = append(it.Tags, tags.Get(i).String())
+	}
+
+	cfs := m.Get(fields.ByName(protoFields)).List()
+	it.CustomFields = make([]CustomField, 0, cfs.Len())
+	for i := 0; i < cfs.Len(); i++ {
+		cf, _ := cfs.Get(i).Message().Interface().(*gophkeeperv1.CustomField)
+		// Неизвестный тип сохраняется числом, чтобы validateMeta указала
+		// на поле с верным индексом.
+		typ, ok := customFieldTypes[cf.GetType()]
+		if !ok {
+			typ = strconv.Itoa(int(cf.GetType()))
+		}
+		it.CustomFields = append(it.CustomFields, CustomField{
+			Name:  cf.GetName(),
+			Type:  typ,
+			Value: cf.GetValue(),
+		})
+	}
+}
+
+func metaToProto(it Item, m protoreflect.Message) {
+	fields := m.Descriptor().Fields()
+	m.Set(fields.ByName(protoFolderID), protoreflect.ValueOfString(it.FolderID))
+
+	tags := m.Mutable(fields.ByName(protoTags)).List()
+	for _, tag := range it.Tags {
+		tags.Append(protoreflect.ValueOfString(tag))
+	}
+	cfs := m.Mutable(fields.ByName(protoFields)).List()
+	for _, cf := range it.CustomFields {
+		pcf := &gophkeeperv1.CustomField{Name: cf.Name, Value: cf.Value}
+		for typ, name := range customFieldTypes {
+			if name == cf.Type {
+				pcf.Type = typ
+			}
+		}
+		cfs.Append(protoreflect.ValueOfMessage(pcf.ProtoReflect()))
+	}
+}
+
+// ItemToProto - запись, обернутая в сообщение Item.
+func (t *Type) ItemToProto(it Item) *gophkeeperv1.Item {
+	res := &gophkeeperv1.Item{}
+	m := res.ProtoReflect()
+	fd := m.Descriptor().Fields().ByName(protoreflect.Name(t.ItemField))
+	m.Set(fd, protoreflect.ValueOfMessage(t.ToProto(it).ProtoReflect()))
+	return res
+}
+
+// MetaToProto - метаданные записи в proto-сообщении.
+func MetaToProto(meta Meta) *gophkeeperv1.ItemMeta {
+	return &gophkeeperv1.ItemMeta{
+		Id:         meta.ID,
+		Type:       meta.Type,
+		Name:       meta.Name,
+		FolderId:   meta.FolderID,
+		Tags:       meta.Tags,
+		Updated:    meta.Updated.Format(time.RFC3339),
+		Deleted:    meta.Deleted,
+		Attributes: meta.Attributes,
+	}
+}
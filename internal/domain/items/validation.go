@@ -0,0 +1,39 @@
+package items
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldViolation - ошибка значения одного поля записи. Field - имя поля
+// в proto-сообщении записи, например "number" или "fields[1].value".
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// ValidationError - ошибки проверки записи по полям.
+type ValidationError struct {
+	Type string
+	Name string
+	// Path - положение записи в запросе, например "cards[2]"; пустой,
+	// если запись проверялась не в составе запроса.
+	Path       string
+	Violations []FieldViolation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.Field+": "+v.Description)
+	}
+	return fmt.Sprintf("%s %q: %s", e.Type, e.Name, strings.Join(parts, "; "))
+}
+
+// FieldPath - полный путь к полю с учетом Path.
+func (e *ValidationError) FieldPath(v FieldViolation) string {
+	if e.Path == "" {
+		return v.Field
+	}
+	return e.Path + "." + v.Field
+}
@@ -1,81 +1,66 @@
 package models
 
-import gophkeeperv1 "github.com/Dorrrke/goph-keeper-proto/gen/go/gophkeeper"
+import "time"
 
-type CardModel struct {
-	Name    string
-	Number  string
-	Date    string
-	CVVCode int
-}
-
-type LoginModel struct {
-	Name     string
-	Login    string
-	Password string
-}
-
-type TextDataModel struct {
-	Name string
-	Data string
-}
-
-type BinaryDataModel struct {
-	Name string
-	Data []byte
-}
-
-type SyncModel struct {
-	Cards []SyncCardModel
-	Texts []SyncTextDataModel
-	Bins  []SyncBinaryDataModel
-	Auth  []SyncLoginModel
-}
-
-type SyncCardModel struct {
-	UserID  int
-	Name    string
-	Number  string
-	Date    string
-	CVVCode int
-	Deleted bool
-	Updated string
-}
-
-type SyncLoginModel struct {
-	UserID   int
-	Name     string
-	Login    string
-	Password string
-	Deleted  bool
-	Updated  string
+type UserModel struct {
+	UserID int64  `json:"u_id" db:"uid"`
+	Login  string `json:"login" db:"login"`
+	// Hash - bcrypt-хеш пароля; пустой у учетных записей, переведенных на SRP.
+	Hash string `json:"hash" db:"hash"`
+	// SRPSalt и SRPVerifier - соль и верификатор SRP-6a.
+	SRPSalt     []byte `json:"-" db:"srp_salt"`
+	SRPVerifier []byte `json:"-" db:"srp_verifier"`
+	// TokenVersion - версия действующих токенов пользователя.
+	TokenVersion int `json:"-" db:"token_version"`
+	// Locked - учетная запись заблокирована администратором.
+	Locked bool `json:"-" db:"locked"`
 }
 
-type SyncTextDataModel struct {
-	UserID  int
-	Name    string
-	Data    string
-	Deleted bool
-	Updated string
+// UserSummary - сведения о пользователе для администратора.
+type UserSummary struct {
+	UserID    int64      `json:"id" db:"uid"`
+	Login     string     `json:"login" db:"login"`
+	SRP       bool       `json:"srp" db:"srp"`
+	LockedAt  *time.Time `json:"locked_at,omitempty" db:"locked_at"`
+	LastLogin *time.Time `json:"last_login,omitempty" db:"last_login_at"`
+	// LastActivity - последнее изменение записей или событие учетной записи.
+	LastActivity *time.Time `json:"last_activity,omitempty" db:"-"`
+	// Items - число неудаленных записей по типам.
+	Items map[string]int `json:"items" db:"-"`
 }
 
-type SyncBinaryDataModel struct {
-	UserID  int
-	Name    string
-	Data    []byte
-	Deleted bool
-	Updated string
+// KeyBundle - ключ хранилища пользователя, обернутый ключом клиента.
+// Сервер не может развернуть ключ и хранит обертку как есть.
+type KeyBundle struct {
+	Name       string    `db:"name"`
+	Algorithm  string    `db:"algorithm"`
+	WrappedKey []byte    `db:"wrapped_key"`
+	Params     []byte    `db:"params"`
+	Updated    time.Time `db:"last_update"`
 }
 
-type ProtoSyncModel struct {
-	Cards []*gophkeeperv1.SyncCard
-	Texts []*gophkeeperv1.SyncText
-	Bins  []*gophkeeperv1.SyncBinData
-	Auth  []*gophkeeperv1.SyncAuth
+// EmergencyContact - доверенный контакт владельца хранилища.
+type EmergencyContact struct {
+	ID           string `db:"id"`
+	OwnerID      int64  `db:"owner_id"`
+	OwnerLogin   string `db:"owner_login"`
+	ContactID    int64  `db:"contact_id"`
+	ContactLogin string `db:"contact_login"`
+	// WaitSeconds - время, за которое владелец может отклонить запрос.
+	WaitSeconds int64 `db:"wait_seconds"`
+	// RequestedAt - время запроса доступа; nil, если запроса нет.
+	RequestedAt *time.Time `db:"requested_at"`
+	// Granted - запрос не отклонен за время ожидания, доступ открыт.
+	Granted bool `db:"granted"`
 }
 
-type UserModel struct {
-	UserID int64  `json:"u_id"`
-	Login  string `json:"login"`
-	Hash   string `json:"hash"`
+// AccountEvent - запись журнала событий учетной записи.
+type AccountEvent struct {
+	ID    int64  `db:"id"`
+	Event string `db:"event"`
+	// ActorLogin - пользователь, выполнивший действие; пустой, если это
+	// владелец учетной записи или пользователь удален.
+	ActorLogin string            `db:"actor_login"`
+	Details    map[string]string `db:"details"`
+	Created    time.Time         `db:"created_at"`
 }
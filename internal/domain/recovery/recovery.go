@@ -0,0 +1,70 @@
+// Package recovery - коды восстановления и события учетной записи.
+package recovery
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"strings"
+)
+
+// codeBytes - энтропия одного кода: 80 бит, 16 символов base32.
+const codeBytes = 10
+
+// codeGroup - длина группы символов в записи кода.
+const codeGroup = 4
+
+// События учетной записи.
+const (
+	EventRecoveryCodesIssued = "recovery_codes_issued"
+	EventRecoveryCodeUsed    = "recovery_code_used"
+	EventContactAdded        = "emergency_contact_added"
+	EventContactRemoved      = "emergency_contact_removed"
+	EventAccessRequested     = "emergency_access_requested"
+	EventAccessDenied        = "emergency_access_denied"
+	EventVaultAccessed       = "emergency_vault_accessed"
+	EventAccountLocked       = "account_locked"
+	EventAccountUnlocked     = "account_unlocked"
+	EventTokensRevoked       = "tokens_revoked"
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewCodes - n случайных кодов вида XXXX-XXXX-XXXX-XXXX.
+func NewCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	buf := make([]byte, codeBytes)
+	for len(codes) < n {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		s := encoding.EncodeToString(buf)
+		groups := make([]string, 0, len(s)/codeGroup)
+		for i := 0; i < len(s); i += codeGroup {
+			groups = append(groups, s[i:i+codeGroup])
+		}
+		codes = append(codes, strings.Join(groups, "-"))
+	}
+	return codes, nil
+}
+
+// HashCode - хеш кода для хранения. Регистр, пробелы и дефисы не учитываются.
+func HashCode(code string) []byte {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return sum[:]
+}
+
+// HashCodes - хеши кодов.
+func HashCodes(codes []string) [][]byte {
+	hashes := make([][]byte, 0, len(codes))
+	for _, c := range codes {
+		hashes = append(hashes, HashCode(c))
+	}
+	return hashes
+}
@@ -0,0 +1,196 @@
+// Package srp - серверная часть протокола SRP-6a (RFC 2945, RFC 5054)
+// для входа без передачи пароля.
+//
+// Параметры: группа 2048 бит из RFC 5054 (приложение A), g = 2, H = SHA-256.
+// PAD(x) - дополнение числа нулями слева до длины N (256 байт), | - конкатенация.
+//
+//	x  = H(salt | H(":" | password))   имя пользователя в x не входит,
+//	                                    поэтому смена логина не меняет верификатор
+//	v  = g^x mod N                     верификатор, хранится на сервере
+//	k  = H(N | PAD(g))
+//	B  = (k*v + g^b) mod N             b - случайное секретное число сервера
+//	u  = H(PAD(A) | PAD(B))
+//	S  = (A * v^u)^b mod N             на клиенте: (B - k*g^x)^(a + u*x) mod N
+//	K  = H(PAD(S))
+//	M1 = H(PAD(A) | PAD(B) | K)        доказательство клиента
+//	M2 = H(PAD(A) | M1 | K)            доказательство сервера
+//
+// Соль - 16 случайных байт. Все числа передаются как big-endian байты.
+package srp
+
+import (
+	"crypto"
+	"crypto/rand"
+	_ "crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"math/big"
+
+	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+)
+
+// SaltSize - размер соли в байтах.
+const SaltSize = 16
+
+// secretSize - размер секретного числа сервера b в байтах.
+const secretSize = 32
+
+// nHex - простое число N группы 2048 бит из RFC 5054.
+const nHex = "" +
+	"AC6BDB41324A9A9BF166DE5E1389582FAF72B6651987EE07FC3192943DB56050" +
+	"A37329CBB4A099ED8193E0757767A13DD52312AB4B03310DCD7F48A9DA04FD50" +
+	"E8083969EDB767B0CF6095179A163AB3661A05FBD5FAAAE82918A9962F0B93B8" +
+	"55F97993EC975EEAA80D740ADBF4FF747359D041D5C33EA71D281E446B14773B" +
+	"CA97B43A23FB801676BD207A436C6481F1D2B9078717461A5B9D32E688F87748" +
+	"544523B524B0D57D5EA77A2775D2ECFA032CFBDBF52FB3786160279004E57AE6" +
+	"AF874E7303CE53299CCC041C7BC308D82A5698F3A8D0C38271AE35F8E9DBFBB6" +
+	"94B5C803D89F7AE435DE236D525F54759B65E372FCD68EF20FA7111F9E4AFF73"
+
+// group - параметры протокола: группа N, g и хеш-функция H.
+type group struct {
+	n    *big.Int
+	g    *big.Int
+	k    *big.Int
+	size int
+	hash crypto.Hash
+}
+
+func newGroup(hexN string, g int64, h crypto.Hash) *group {
+	n, _ := new(big.Int).SetString(hexN, 16)
+	grp := &group{n: n, g: big.NewInt(g), size: len(n.Bytes()), hash: h}
+	grp.k = new(big.Int).SetBytes(grp.h(n.Bytes(), grp.pad(grp.g)))
+	return grp
+}
+
+// defaultGroup - группа 2048 бит из RFC 5054 с H = SHA-256.
+var defaultGroup = newGroup(nHex, 2, crypto.SHA256)
+
+var (
+	ErrInvalidPublicKey = errors.New(errText.SRPInvalidPublicKeyError)
+	ErrProofMismatch    = errors.New(errText.SRPProofMismatchError)
+)
+
+func (grp *group) h(parts ...[]byte) []byte {
+	h := grp.hash.New()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
+
+func (grp *group) pad(x *big.Int) []byte {
+	return x.FillBytes(make([]byte, grp.size))
+}
+
+// verifier - v = g^x mod N.
+func (grp *group) verifier(x *big.Int) *big.Int {
+	return new(big.Int).Exp(grp.g, x, grp.n)
+}
+
+// publicB - B = (k*v + g^b) mod N.
+func (grp *group) publicB(v, b *big.Int) *big.Int {
+	pubB := new(big.Int).Mul(grp.k, v)
+	pubB.Add(pubB, new(big.Int).Exp(grp.g, b, grp.n))
+	return pubB.Mod(pubB, grp.n)
+}
+
+// scrambler - u = H(PAD(A) | PAD(B)).
+func (grp *group) scrambler(a, pubB *big.Int) *big.Int {
+	return new(big.Int).SetBytes(grp.h(grp.pad(a), grp.pad(pubB)))
+}
+
+// premaster - S = (A * v^u)^b mod N.
+func (grp *group) premaster(a, v, u, b *big.Int) *big.Int {
+	s := new(big.Int).Exp(v, u, grp.n)
+	s.Mul(s, a)
+	s.Mod(s, grp.n)
+	return s.Exp(s, b, grp.n)
+}
+
+// NewSalt - случайная соль.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	_, err := rand.Read(salt)
+	return salt, err
+}
+
+// Verifier - верификатор v для пароля и соли. Используется сервером только
+// при регистрации и входе старых клиентов, передающих пароль.
+func Verifier(salt []byte, password string) []byte {
+	return defaultGroup.verifier(passwordKey(salt, password)).Bytes()
+}
+
+// passwordKey - x = H(salt | H(":" | password)).
+func passwordKey(salt []byte, password string) *big.Int {
+	return new(big.Int).SetBytes(defaultGroup.h(salt, defaultGroup.h([]byte(":"+password))))
+}
+
+// CheckPassword - соответствие пароля верификатору. Верификатор клиента
+// может быть дополнен нулями слева, поэтому сравниваются числа, дополненные
+// до длины N.
+func CheckPassword(salt, verifier []byte, password string) bool {
+	if !ValidVerifier(verifier) {
+		return false
+	}
+	v := new(big.Int).SetBytes(verifier)
+	want := defaultGroup.verifier(passwordKey(salt, password))
+	return subtle.ConstantTimeCompare(defaultGroup.pad(want), defaultGroup.pad(v)) == 1
+}
+
+// ValidVerifier - верификатор является элементом группы.
+func ValidVerifier(verifier []byte) bool {
+	v := new(big.Int).SetBytes(verifier)
+	return v.Sign() > 0 && v.Cmp(defaultGroup.n) < 0
+}
+
+// Handshake - состояние сервера между первым и вторым шагом входа.
+type Handshake struct {
+	// A - открытое число клиента.
+	A []byte
+	// Secret - секретное число сервера b.
+	Secret []byte
+	// B - открытое число сервера.
+	B []byte
+}
+
+// Start - первый шаг: проверка A клиента и вычисление B сервера.
+func Start(verifier, clientA []byte) (Handshake, error) {
+	grp := defaultGroup
+	a := new(big.Int).SetBytes(clientA)
+	if new(big.Int).Mod(a, grp.n).Sign() == 0 {
+		return Handshake{}, ErrInvalidPublicKey
+	}
+	v := new(big.Int).SetBytes(verifier)
+	for {
+		secret := make([]byte, secretSize)
+		if _, err := rand.Read(secret); err != nil {
+			return Handshake{}, err
+		}
+		pubB := grp.publicB(v, new(big.Int).SetBytes(secret))
+		if pubB.Sign() == 0 {
+			continue
+		}
+		return Handshake{A: grp.pad(a), Secret: secret, B: grp.pad(pubB)}, nil
+	}
+}
+
+// Verify - второй шаг: проверка доказательства клиента M1. Возвращает
+// доказательство сервера M2.
+func (h Handshake) Verify(verifier, clientM1 []byte) ([]byte, error) {
+	grp := defaultGroup
+	a := new(big.Int).SetBytes(h.A)
+	pubB := new(big.Int).SetBytes(h.B)
+	u := grp.scrambler(a, pubB)
+	if u.Sign() == 0 {
+		return nil, ErrInvalidPublicKey
+	}
+	v := new(big.Int).SetBytes(verifier)
+	s := grp.premaster(a, v, u, new(big.Int).SetBytes(h.Secret))
+	k := grp.h(grp.pad(s))
+
+	m1 := grp.h(grp.pad(a), grp.pad(pubB), k)
+	if subtle.ConstantTimeCompare(m1, clientM1) != 1 {
+		return nil, ErrProofMismatch
+	}
+	return grp.h(grp.pad(a), m1, k), nil
+}
@@ -0,0 +1,200 @@
+package srp
+
+import (
+	"bytes"
+	"crypto"
+	_ "crypto/sha1"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func num(t *testing.T, hexStr string) *big.Int {
+	t.Helper()
+	n, ok := new(big.Int).SetString(strings.ReplaceAll(hexStr, " ", ""), 16)
+	if !ok {
+		t.Fatalf("bad hex %q", hexStr)
+	}
+	return n
+}
+
+// TestGroupRFC5054 - тестовые векторы RFC 5054 (приложение B): группа
+// 1024 бит, H = SHA-1. Значение x задано вектором, так как в RFC в x входит
+// имя пользователя.
+func TestGroupRFC5054(t *testing.T) {
+	const n1024 = "EEAF0AB9 ADB38DD6 9C33F80A FA8FC5E8 60726187 75FF3C0B 9EA2314C" +
+		"9C256576 D674DF74 96EA81D3 383B4813 D692C6E0 E0D5D8E2 50B98BE4" +
+		"8E495C1D 6089DAD1 5DC7D7B4 6154D6B6 CE8EF4AD 69B15D49 82559B29" +
+		"7BCF1885 C529F566 660E57EC 68EDBC3C 05726CC0 2FD4CBF4 976EAA9A" +
+		"FD5138FE 8376435B 9FC61D2F C0EB06E3"
+	grp := newGroup(strings.ReplaceAll(n1024, " ", ""), 2, crypto.SHA1)
+
+	x := num(t, "94B7555A ABE9127C C58CCF49 93DB6CF8 4D16C124")
+	a := num(t, "60975527 035CF2AD 1989806F 0407210B C81EDC04 E2762A56 AFD529DD DA2D4393")
+	b := num(t, "E487CB59 D31AC550 471E81F0 0F6928E0 1DDA08E9 74A004F4 9E61F5D1 05284D20")
+	pubA := new(big.Int).Exp(grp.g, a, grp.n)
+
+	v := grp.verifier(x)
+	pubB := grp.publicB(v, b)
+	u := grp.scrambler(pubA, pubB)
+	s := grp.premaster(pubA, v, u, b)
+
+	// Сторона клиента: S = (B - k*g^x)^(a + u*x) mod N.
+	base := new(big.Int).Mul(grp.k, new(big.Int).Exp(grp.g, x, grp.n))
+	base.Sub(pubB, base)
+	base.Mod(base, grp.n)
+	exp := new(big.Int).Mul(u, x)
+	exp.Add(exp, a)
+	clientS := new(big.Int).Exp(base, exp, grp.n)
+
+	tests := []struct {
+		name string
+		got  *big.Int
+		want string
+	}{
+		{"k", grp.k, "7556AA04 5AEF2CDD 07ABAF0F 665C3E81 8913186F"},
+		{"v", v, "7E273DE8 696FFC4F 4E337D05 B4B375BE B0DDE156 9E8FA00A 9886D812" +
+			"9BADA1F1 822223CA 1A605B53 0E379BA4 729FDC59 F105B478 7E5186F5" +
+			"C671085A 1447B52A 48CF1970 B4FB6F84 00BBF4CE BFBB1681 52E08AB5" +
+			"EA53D15C 1AFF87B2 B9DA6E04 E058AD51 CC72BFC9 033B564E 26480D78" +
+			"E955A5E2 9E7AB245 DB2BE315 E2099AFB"},
+		{"A", pubA, "61D5E490 F6F1B795 47B0704C 436F523D D0E560F0 C64115BB 72557EC4" +
+			"4352E890 3211C046 92272D8B 2D1A5358 A2CF1B6E 0BFCF99F 921530EC" +
+			"8E393561 79EAE45E 42BA92AE ACED8251 71E1E8B9 AF6D9C03 E1327F44" +
+			"BE087EF0 6530E69F 66615261 EEF54073 CA11CF58 58F0EDFD FE15EFEA" +
+			"B349EF5D 76988A36 72FAC47B 0769447B"},
+		{"B", pubB, "BD0C6151 2C692C0C B6D041FA 01BB152D 4916A1E7 7AF46AE1 05393011" +
+			"BAF38964 DC46A067 0DD125B9 5A981652 236F99D9 B681CBF8 7837EC99" +
+			"6C6DA044 53728610 D0C6DDB5 8B318885 D7D82C7F 8DEB75CE 7BD4FBAA" +
+			"37089E6F 9C6059F3 88838E7A 00030B33 1EB76840 910440B1 B27AAEAE" +
+			"EB4012B7 D7665238 A8E3FB00 4B117B58"},
+		{"u", u, "CE38B959 3487DA98 554ED47D 70A7AE5F 462EF019"},
+		{"S", s, "B0DC82BA BCF30674 AE450C02 87745E79 90A3381F 63B387AA F271A10D" +
+			"233861E3 59B48220 F7C4693C 9AE12B0A 6F67809F 0876E2D0 13800D6C" +
+			"41BB59B6 D5979B5C 00A172B4 A2A5903A 0BDCAF8A 709585EB 2AFAFA8F" +
+			"3499B200 210DCC1F 10EB3394 3CD67FC8 8A2F39A4 BE5BEC4E C0A3212D" +
+			"C346D7E4 74B29EDE 8A469FFE CA686E5A"},
+		{"client S", clientS, "B0DC82BA BCF30674 AE450C02 87745E79 90A3381F 63B387AA F271A10D" +
+			"233861E3 59B48220 F7C4693C 9AE12B0A 6F67809F 0876E2D0 13800D6C" +
+			"41BB59B6 D5979B5C 00A172B4 A2A5903A 0BDCAF8A 709585EB 2AFAFA8F" +
+			"3499B200 210DCC1F 10EB3394 3CD67FC8 8A2F39A4 BE5BEC4E C0A3212D" +
+			"C346D7E4 74B29EDE 8A469FFE CA686E5A"},
+	}
+	for _, tt := range tests {
+		if want := num(t, tt.want); tt.got.Cmp(want) != 0 {
+			t.Errorf("%s = %X, want %X", tt.name, tt.got, want)
+		}
+	}
+}
+
+// client - клиентская сторона входа в группе по умолчанию.
+func client(salt []byte, password string, a *big.Int, h Handshake) (m1, m2 []byte) {
+	grp := defaultGroup
+	x := passwordKey(salt, password)
+	pubA := new(big.Int).Exp(grp.g, a, grp.n)
+	pubB := new(big.Int).SetBytes(h.B)
+	u := grp.scrambler(pubA, pubB)
+	base := new(big.Int).Mul(grp.k, grp.verifier(x))
+	base.Sub(pubB, base)
+	base.Mod(base, grp.n)
+	exp := new(big.Int).Mul(u, x)
+	exp.Add(exp, a)
+	k := grp.h(grp.pad(new(big.Int).Exp(base, exp, grp.n)))
+	m1 = grp.h(grp.pad(pubA), grp.pad(pubB), k)
+	return m1, grp.h(grp.pad(pubA), m1, k)
+}
+
+func TestHandshake(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	verifier := Verifier(salt, "secret")
+	a := big.NewInt(123456789)
+	pubA := new(big.Int).Exp(defaultGroup.g, a, defaultGroup.n).Bytes()
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{"correct password", "secret", nil},
+		{"wrong password", "Secret", ErrProofMismatch},
+		{"empty password", "", ErrProofMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := Start(verifier, pubA)
+			if err != nil {
+				t.Fatalf("Start: %v", err)
+			}
+			m1, wantM2 := client(salt, tt.password, a, h)
+			m2, err := h.Verify(verifier, m1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !bytes.Equal(m2, wantM2) {
+				t.Errorf("M2 = %x, want %x", m2, wantM2)
+			}
+		})
+	}
+}
+
+func TestStartRejectsPublicKey(t *testing.T) {
+	verifier := Verifier([]byte("salt"), "secret")
+	tests := []struct {
+		name string
+		a    []byte
+	}{
+		{"zero", []byte{0}},
+		{"empty", nil},
+		{"N", defaultGroup.n.Bytes()},
+		{"2N", new(big.Int).Lsh(defaultGroup.n, 1).Bytes()},
+	}
+	for _, tt := range tests {
+		if _, err := Start(verifier, tt.a); !errors.Is(err, ErrInvalidPublicKey) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, ErrInvalidPublicKey)
+		}
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	verifier := Verifier(salt, "secret")
+	tests := []struct {
+		name     string
+		verifier []byte
+		password string
+		want     bool
+	}{
+		{"correct", verifier, "secret", true},
+		{"wrong", verifier, "secret1", false},
+		{"left-padded verifier", append([]byte{0, 0}, verifier...), "secret", true},
+		{"padded to N", defaultGroup.pad(new(big.Int).SetBytes(verifier)), "secret", true},
+		{"empty verifier", nil, "secret", false},
+		{"verifier above N", append([]byte{1}, make([]byte, defaultGroup.size)...), "secret", false},
+	}
+	for _, tt := range tests {
+		if got := CheckPassword(salt, tt.verifier, tt.password); got != tt.want {
+			t.Errorf("%s: CheckPassword = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidVerifier(t *testing.T) {
+	nMinus1 := new(big.Int).Sub(defaultGroup.n, big.NewInt(1))
+	tests := []struct {
+		name     string
+		verifier []byte
+		want     bool
+	}{
+		{"computed", Verifier([]byte("salt"), "secret"), true},
+		{"zero", []byte{0}, false},
+		{"empty", nil, false},
+		{"N-1", nMinus1.Bytes(), true},
+		{"N", defaultGroup.n.Bytes(), false},
+	}
+	for _, tt := range tests {
+		if got := ValidVerifier(tt.verifier); got != tt.want {
+			t.Errorf("%s: ValidVerifier = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
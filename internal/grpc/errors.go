@@ -0,0 +1,186 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/archive"
+	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/importer"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/srp"
+	"github.com/Dorrrke/GophKeeper-server/internal/service"
+	"github.com/Dorrrke/GophKeeper-server/internal/storage"
+	"github.com/Dorrrke/GophKeeper-server/internal/validation"
+	gophkeeperv1 "github.com/Dorrrke/goph-keeper-proto/gen/go/gophkeeper"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// errorDomain - домен причин ошибок в ErrorInfo.
+const errorDomain = "gophkeeper"
+
+var (
+	ErrMissingMetadata      = errors.New(errText.MetadataError)
+	ErrMissingAuthorization = errors.New(errText.MissingAuthorizationKeyError)
+	ErrTooManySyncItems     = errors.New(errText.TooManySyncItemsError)
+)
+
+// errorRule - код gRPC и причина для доменной ошибки.
+type errorRule struct {
+	err    error
+	code   codes.Code
+	reason gophkeeperv1.ErrorReason
+	// retry - задержка для RetryInfo; 0, если повтор того же запроса
+	// не поможет.
+	retry time.Duration
+}
+
+// errorRules - соответствие всех доменных ошибок статусам gRPC.
+var errorRules = []errorRule{
+	{ErrMissingMetadata, codes.Unauthenticated, gophkeeperv1.ErrorReason_AUTH_REQUIRED, 0},
+	{ErrMissingAuthorization, codes.Unauthenticated, gophkeeperv1.ErrorReason_AUTH_REQUIRED, 0},
+	{ErrInvalidToken, codes.Unauthenticated, gophkeeperv1.ErrorReason_INVALID_TOKEN, 0},
+	{service.ErrTokenRevoked, codes.Unauthenticated, gophkeeperv1.ErrorReason_TOKEN_REVOKED, 0},
+
+	{service.ErrInvalidPassword, codes.Unauthenticated, gophkeeperv1.ErrorReason_INVALID_CREDENTIALS, 0},
+	{srp.ErrProofMismatch, codes.Unauthenticated, gophkeeperv1.ErrorReason_INVALID_CREDENTIALS, 0},
+	// Входы по логину отвечают на неизвестный логин INVALID_CREDENTIALS;
+	// USER_NOT_FOUND возвращают только вызовы с токеном.
+	{storage.ErrUserNotExist, codes.NotFound, gophkeeperv1.ErrorReason_USER_NOT_FOUND, 0},
+	{storage.ErrUserAlredyExist, codes.AlreadyExists, gophkeeperv1.ErrorReason_USER_EXISTS, 0},
+	{service.ErrAccountLocked, codes.PermissionDenied, gophkeeperv1.ErrorReason_ACCOUNT_LOCKED, 0},
+	{storage.ErrSRPSessionNotExist, codes.Unauthenticated, gophkeeperv1.ErrorReason_SRP_SESSION_EXPIRED, 0},
+	{service.ErrInvalidSRPVerifier, codes.InvalidArgument, gophkeeperv1.ErrorReason_INVALID_SRP_VERIFIER, 0},
+	{srp.ErrInvalidPublicKey, codes.InvalidArgument, gophkeeperv1.ErrorReason_INVALID_SRP_PUBLIC_KEY, 0},
+	{service.ErrEmptyLogin, codes.InvalidArgument, gophkeeperv1.ErrorReason_EMPTY_LOGIN, 0},
+	{service.ErrEmptyPassword, codes.InvalidArgument, gophkeeperv1.ErrorReason_EMPTY_PASSWORD, 0},
+	{service.ErrSamePassword, codes.InvalidArgument, gophkeeperv1.ErrorReason_SAME_PASSWORD, 0},
+	{service.ErrLoginMismatch, codes.InvalidArgument, gophkeeperv1.ErrorReason_LOGIN_MISMATCH, 0},
+
+	{storage.ErrInvalidRecoveryCode, codes.Unauthenticated, gophkeeperv1.ErrorReason_INVALID_RECOVERY_CODE, 0},
+	{storage.ErrKeyBundleNotExist, codes.NotFound, gophkeeperv1.ErrorReason_KEY_BUNDLE_NOT_FOUND, 0},
+	{service.ErrInvalidKeyBundle, codes.InvalidArgument, gophkeeperv1.ErrorReason_INVALID_KEY_BUNDLE, 0},
+	{storage.ErrTooManyKeyBundles, codes.ResourceExhausted, gophkeeperv1.ErrorReason_TOO_MANY_KEY_BUNDLES, 0},
+	{storage.ErrEmergencyContactNotExist, codes.NotFound, gophkeeperv1.ErrorReason_EMERGENCY_CONTACT_NOT_FOUND, 0},
+	{storage.ErrEmergencyContactExists, codes.AlreadyExists, gophkeeperv1.ErrorReason_EMERGENCY_CONTACT_EXISTS, 0},
+	{service.ErrSelfEmergencyContact, codes.InvalidArgument, gophkeeperv1.ErrorReason_SELF_EMERGENCY_CONTACT, 0},
+	{service.ErrInvalidEmergencyWait, codes.InvalidArgument, gophkeeperv1.ErrorReason_INVALID_EMERGENCY_WAIT, 0},
+	{storage.ErrEmergencyAccessNotRequested, codes.FailedPrecondition, gophkeeperv1.ErrorReason_EMERGENCY_ACCESS_NOT_REQUESTED, 0},
+	{storage.ErrEmergencyAccessNotGranted, codes.FailedPrecondition, gophkeeperv1.ErrorReason_EMERGENCY_ACCESS_NOT_GRANTED, 0},
+
+	{storage.ErrItemNotExist, codes.NotFound, gophkeeperv1.ErrorReason_ITEM_NOT_FOUND, 0},
+	{storage.ErrItemIDConflict, codes.InvalidArgument, gophkeeperv1.ErrorReason_ITEM_ID_CONFLICT, 0},
+	{storage.ErrFolderNotExist, codes.InvalidArgument, gophkeeperv1.ErrorReason_FOLDER_NOT_FOUND, 0},
+	{service.ErrUnknownItemType, codes.InvalidArgument, gophkeeperv1.ErrorReason_UNKNOWN_ITEM_TYPE, 0},
+	{service.ErrInvalidFilter, codes.InvalidArgument, gophkeeperv1.ErrorReason_INVALID_FILTER, 0},
+	{service.ErrInvalidPageToken, codes.InvalidArgument, gophkeeperv1.ErrorReason_INVALID_PAGE_TOKEN, 0},
+	{ErrTooManySyncItems, codes.InvalidArgument, gophkeeperv1.ErrorReason_TOO_MANY_SYNC_ITEMS, 0},
+	{service.ErrInvalidIdempotencyKey, codes.InvalidArgument, gophkeeperv1.ErrorReason_INVALID_IDEMPOTENCY_KEY, 0},
+	{storage.ErrIdempotencyKeyReused, codes.InvalidArgument, gophkeeperv1.ErrorReason_IDEMPOTENCY_KEY_REUSED, 0},
+	{storage.ErrSyncInProgress, codes.Aborted, gophkeeperv1.ErrorReason_SYNC_IN_PROGRESS, 2 * time.Second},
+	{service.ErrSyncPageItems, codes.InvalidArgument, gophkeeperv1.ErrorReason_SYNC_PAGE_WITH_ITEMS, 0},
+	{storage.ErrSyncCursorNotExist, codes.FailedPrecondition, gophkeeperv1.ErrorReason_SYNC_PAGE_EXPIRED, 0},
+	{storage.ErrConcurrentUpdate, codes.Aborted, gophkeeperv1.ErrorReason_CONCURRENT_UPDATE, 100 * time.Millisecond},
+
+	{archive.ErrInvalidArchive, codes.InvalidArgument, gophkeeperv1.ErrorReason_INVALID_ARCHIVE, 0},
+	{archive.ErrUnsupportedVersion, codes.InvalidArgument, gophkeeperv1.ErrorReason_UNSUPPORTED_ARCHIVE_VERSION, 0},
+	{archive.ErrWeakPassphrase, codes.InvalidArgument, gophkeeperv1.ErrorReason_WEAK_PASSPHRASE, 0},
+	{archive.ErrWrongPassphrase, codes.PermissionDenied, gophkeeperv1.ErrorReason_WRONG_PASSPHRASE, 0},
+	{service.ErrUnknownDuplicatePolicy, codes.InvalidArgument, gophkeeperv1.ErrorReason_UNKNOWN_DUPLICATE_POLICY, 0},
+	{service.ErrUnknownImportFormat, codes.InvalidArgument, gophkeeperv1.ErrorReason_UNKNOWN_IMPORT_FORMAT, 0},
+	{importer.ErrInvalidFile, codes.InvalidArgument, gophkeeperv1.ErrorReason_INVALID_IMPORT_FILE, 0},
+	{importer.ErrInvalidMapping, codes.InvalidArgument, gophkeeperv1.ErrorReason_INVALID_CSV_MAPPING, 0},
+	{importer.ErrImportTooLarge, codes.InvalidArgument, gophkeeperv1.ErrorReason_IMPORT_TOO_LARGE, 0},
+}
+
+// rpcError - статус gRPC для ошибки обработчика: код, ErrorInfo с причиной,
+// BadRequest для ошибок проверки записей и RetryInfo для временных ошибок.
+// Неизвестные ошибки записываются в журнал с msg и скрываются от клиента.
+func (k *KeepServer) rpcError(err error, msg string) error {
+	return k.rpcErrorWith(err, msg, nil)
+}
+
+// rpcErrorWith - rpcError с дополнительными полями metadata в ErrorInfo.
+func (k *KeepServer) rpcErrorWith(err error, msg string, meta map[string]string) error {
+	var reqErr *validation.Error
+	if errors.As(err, &reqErr) {
+		return requestValidationStatus(reqErr)
+	}
+	var vErr *items.ValidationError
+	if errors.As(err, &vErr) {
+		return validationStatus(vErr)
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	for _, r := range errorRules {
+		if errors.Is(err, r.err) {
+			return newStatus(r.code, r.reason, err.Error(), meta, r.retry)
+		}
+	}
+	k.zlog.Error().Err(err).Msg(msg)
+	return newStatus(codes.Internal, gophkeeperv1.ErrorReason_INTERNAL, "internal error", nil, 0)
+}
+
+// newStatus - статус с ErrorInfo и, если retry больше нуля, RetryInfo.
+func newStatus(code codes.Code, reason gophkeeperv1.ErrorReason, msg string, meta map[string]string,
+	retry time.Duration) error {
+	st := status.New(code, msg)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: reason.String(), Domain: errorDomain, Metadata: meta}}
+	if retry > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retry)})
+	}
+	return withDetails(st, details...)
+}
+
+// validationStatus - InvalidArgument с ошибками по полям в BadRequest.
+func validationStatus(vErr *items.ValidationError) error {
+	br := &errdetails.BadRequest{}
+	for _, v := range vErr.Violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       vErr.FieldPath(v),
+			Description: v.Description,
+		})
+	}
+	info := &errdetails.ErrorInfo{
+		Reason:   gophkeeperv1.ErrorReason_VALIDATION_FAILED.String(),
+		Domain:   errorDomain,
+		Metadata: map[string]string{"type": vErr.Type},
+	}
+	return withDetails(status.New(codes.InvalidArgument, vErr.Error()), info, br)
+}
+
+// requestValidationStatus - InvalidArgument со всеми ошибками запроса
+// по полям в BadRequest.
+func requestValidationStatus(reqErr *validation.Error) error {
+	br := &errdetails.BadRequest{}
+	for _, v := range reqErr.Violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+	info := &errdetails.ErrorInfo{
+		Reason: gophkeeperv1.ErrorReason_VALIDATION_FAILED.String(),
+		Domain: errorDomain,
+	}
+	return withDetails(status.New(codes.InvalidArgument, reqErr.Error()), info, br)
+}
+
+// withDetails - ошибка статуса st с деталями; если детали не удалось
+// сериализовать, возвращается статус без них.
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	withDet, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return withDet.Err()
+}
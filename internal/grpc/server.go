@@ -6,138 +6,502 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/Dorrrke/GophKeeper-server/internal/config"
 	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
-	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
 	"github.com/Dorrrke/GophKeeper-server/internal/service"
-	"github.com/Dorrrke/GophKeeper-server/internal/storage"
+	"github.com/Dorrrke/GophKeeper-server/internal/validation"
 	gophkeeperv1 "github.com/Dorrrke/goph-keeper-proto/gen/go/gophkeeper"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
-	"google.golang.org/grpc/status"
 )
 
 type Interceptor func(context.Context, interface{}, *grpc.UnaryServerInfo, grpc.UnaryHandler) (interface{}, error)
 
 var ErrInvalidToken = errors.New(errText.InvalidTokenError)
 
-const SecretKey = "Secret123Key345Super"
-
 type Claims struct {
 	jwt.RegisteredClaims
 	UserID string
+	// TokenVersion - версия токенов пользователя на момент выдачи.
+	TokenVersion int `json:"ver"`
 }
 
 type KeepServer struct {
 	gophkeeperv1.UnimplementedGophKeeperServer
 	keepService *service.KeepService
+	jwtCfg      config.JWTConfig
+	limits      config.LimitsConfig
 	zlog        *zerolog.Logger
 }
 
-func RegisterGrpcServer(gRPC *grpc.Server, service *service.KeepService, log *zerolog.Logger) {
-	gophkeeperv1.RegisterGophKeeperServer(gRPC, &KeepServer{keepService: service, zlog: log})
+func RegisterGrpcServer(gRPC *grpc.Server, service *service.KeepService, cfg *config.Config, log *zerolog.Logger) {
+	gophkeeperv1.RegisterGophKeeperServer(gRPC, &KeepServer{
+		keepService: service,
+		jwtCfg:      cfg.JWT,
+		limits:      cfg.Limits,
+		zlog:        log,
+	})
 }
 
 func (k *KeepServer) SignIn(ctx context.Context, req *gophkeeperv1.SingInRequest) (*gophkeeperv1.SignInResponse, error) {
+	if err := validation.SignIn(req); err != nil {
+		return nil, k.rpcError(err, "")
+	}
 	user, err := k.keepService.LoginUser(req.GetLogin(), req.GetPassword())
 	if err != nil {
-		if errors.Is(err, storage.ErrUserNotExist) {
-			k.zlog.Error().Err(err).Msg("invalid login/password pair. This user does not exist.")
-			return nil, status.Error(codes.Unauthenticated, errText.InvalidLoginUserError)
-		}
-		if errors.Is(err, service.ErrInvalidPassword) {
-			k.zlog.Error().Err(err).Msg("invalid password")
-			return nil, status.Error(codes.Unauthenticated, errText.InvalidPasswordError)
+		if errors.Is(err, service.ErrAccountLocked) {
+			k.zlog.Info().Str("login", req.GetLogin()).Msg("sign in to a locked account")
 		}
-		k.zlog.Error().Err(err).Msg("error during user authentication attempt")
-		return nil, status.Error(codes.Internal, "internal error")
+		return nil, k.rpcError(err, "error during user authentication attempt")
 	}
-	jwtToken, err := createJWTToken(user.UserID)
+	bundles, err := k.keepService.KeyBundles(ctx, int(user.UserID))
 	if err != nil {
-		k.zlog.Error().Err(err).Msg("error during JWT token creation")
-		return nil, status.Error(codes.Internal, "internal error")
+		return nil, k.rpcError(err, "get key bundles error")
 	}
-	header := metadata.Pairs("Authorization", jwtToken)
-	grpc.SendHeader(ctx, header)
-	return &gophkeeperv1.SignInResponse{}, nil
+	if err := k.sendToken(ctx, user.UserID, user.TokenVersion); err != nil {
+		return nil, err
+	}
+	return &gophkeeperv1.SignInResponse{KeyBundles: bundles}, nil
 }
 
 func (k *KeepServer) SignUp(ctx context.Context, req *gophkeeperv1.SignUpRequest) (*gophkeeperv1.SignUpResponse, error) {
-	uid, err := k.keepService.RegisterUser(req.GetLogin(), req.GetPassword())
+	if err := validation.SignUp(req); err != nil {
+		return nil, k.rpcError(err, "")
+	}
+	uid, recoveryCodes, err := k.keepService.RegisterUser(req.GetLogin(), req.GetPassword())
 	if err != nil {
-		if errors.Is(err, storage.ErrUserAlredyExist) {
-			k.zlog.Error().Err(err).Msg("user alredy exist")
-			return nil, status.Error(codes.Canceled, errText.UserExistsError)
-		}
-		k.zlog.Error().Err(err).Msg("error during user registration attempt")
-		return nil, status.Error(codes.Internal, "internal error")
+		return nil, k.rpcError(err, "error during user registration attempt")
 	}
-	jwtToken, err := createJWTToken(uid)
+	jwtToken, err := k.createJWTToken(uid, 0)
 	if err != nil {
-		k.zlog.Error().Err(err).Msg("error during JWT token creation")
-		return nil, status.Error(codes.Internal, "internal error")
+		return nil, k.rpcError(err, "error during JWT token creation")
 	}
 	header := metadata.Pairs("Authorization", jwtToken)
 	grpc.SendHeader(ctx, header)
 	k.zlog.Debug().Str("Token", jwtToken)
-	return &gophkeeperv1.SignUpResponse{}, nil
+	return &gophkeeperv1.SignUpResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (k *KeepServer) SrpRegister(ctx context.Context, req *gophkeeperv1.SrpRegisterRequest) (*gophkeeperv1.SrpRegisterResponse, error) {
+	if err := validation.SrpRegister(req); err != nil {
+		return nil, k.rpcError(err, "")
+	}
+	uid, recoveryCodes, err := k.keepService.RegisterSRP(ctx, req.GetLogin(), req.GetSalt(), req.GetVerifier())
+	if err != nil {
+		return nil, k.rpcError(err, "srp registration error")
+	}
+	if err := k.sendToken(ctx, uid, 0); err != nil {
+		return nil, err
+	}
+	return &gophkeeperv1.SrpRegisterResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (k *KeepServer) SrpStart(ctx context.Context, req *gophkeeperv1.SrpStartRequest) (*gophkeeperv1.SrpStartResponse, error) {
+	if err := validation.SrpStart(req); err != nil {
+		return nil, k.rpcError(err, "")
+	}
+	id, salt, b, err := k.keepService.StartSRP(ctx, req.GetLogin(), req.GetA())
+	if err != nil {
+		return nil, k.rpcError(err, "srp start error")
+	}
+	return &gophkeeperv1.SrpStartResponse{SessionId: id, Salt: salt, B: b}, nil
+}
+
+func (k *KeepServer) SrpVerify(ctx context.Context, req *gophkeeperv1.SrpVerifyRequest) (*gophkeeperv1.SrpVerifyResponse, error) {
+	user, m2, err := k.keepService.VerifySRP(ctx, req.GetSessionId(), req.GetM1())
+	if err != nil {
+		return nil, k.rpcError(err, "srp verify error")
+	}
+	bundles, err := k.keepService.KeyBundles(ctx, int(user.UserID))
+	if err != nil {
+		return nil, k.rpcError(err, "get key bundles error")
+	}
+	if err := k.sendToken(ctx, user.UserID, user.TokenVersion); err != nil {
+		return nil, err
+	}
+	return &gophkeeperv1.SrpVerifyResponse{M2: m2, KeyBundles: bundles}, nil
+}
+
+// sendToken - новый jwt токен в заголовке Authorization ответа.
+func (k *KeepServer) sendToken(ctx context.Context, uid int64, version int) error {
+	jwtToken, err := k.createJWTToken(uid, version)
+	if err != nil {
+		return k.rpcError(err, "error during JWT token creation")
+	}
+	header := metadata.Pairs("Authorization", jwtToken)
+	grpc.SendHeader(ctx, header)
+	return nil
+}
+
+func (k *KeepServer) ChangePassword(ctx context.Context, req *gophkeeperv1.ChangePasswordRequest) (*gophkeeperv1.ChangePasswordResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := k.keepService.ChangePassword(ctx, uID, req.GetSrpProof(), req.GetCurrentPassword(),
+		req.GetNewPassword(), req.GetNewSrpSalt(), req.GetNewSrpVerifier(), req.GetKeyBundles())
+	if err != nil {
+		return nil, k.rpcError(err, "change password error")
+	}
+	if err := k.sendToken(ctx, user.UserID, user.TokenVersion); err != nil {
+		return nil, err
+	}
+	return &gophkeeperv1.ChangePasswordResponse{}, nil
+}
+
+func (k *KeepServer) ChangeLogin(ctx context.Context, req *gophkeeperv1.ChangeLoginRequest) (*gophkeeperv1.ChangeLoginResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.ChangeLogin(req); err != nil {
+		return nil, k.rpcError(err, "")
+	}
+	if err := k.keepService.ChangeLogin(ctx, uID, req.GetSrpProof(), req.GetPassword(), req.GetNewLogin()); err != nil {
+		return nil, k.rpcError(err, "change login error")
+	}
+	return &gophkeeperv1.ChangeLoginResponse{}, nil
+}
+
+func (k *KeepServer) DeleteAccount(ctx context.Context, req *gophkeeperv1.DeleteAccountRequest) (*gophkeeperv1.DeleteAccountResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.keepService.DeleteAccount(ctx, uID, req.GetSrpProof(), req.GetPassword(), req.GetLogin()); err != nil {
+		return nil, k.rpcError(err, "delete account error")
+	}
+	return &gophkeeperv1.DeleteAccountResponse{}, nil
+}
+
+func (k *KeepServer) GetKeyBundles(ctx context.Context, req *gophkeeperv1.GetKeyBundlesRequest) (*gophkeeperv1.GetKeyBundlesResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bundles, err := k.keepService.KeyBundles(ctx, uID)
+	if err != nil {
+		return nil, k.rpcError(err, "get key bundles error")
+	}
+	return &gophkeeperv1.GetKeyBundlesResponse{KeyBundles: bundles}, nil
+}
+
+func (k *KeepServer) PutKeyBundle(ctx context.Context, req *gophkeeperv1.PutKeyBundleRequest) (*gophkeeperv1.PutKeyBundleResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bundle, err := k.keepService.PutKeyBundle(ctx, uID, req.GetKeyBundle())
+	if err != nil {
+		return nil, k.rpcError(err, "put key bundle error")
+	}
+	return &gophkeeperv1.PutKeyBundleResponse{KeyBundle: bundle}, nil
+}
+
+func (k *KeepServer) DeleteKeyBundle(ctx context.Context, req *gophkeeperv1.DeleteKeyBundleRequest) (*gophkeeperv1.DeleteKeyBundleResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.keepService.DeleteKeyBundle(ctx, uID, req.GetName()); err != nil {
+		return nil, k.rpcError(err, "delete key bundle error")
+	}
+	return &gophkeeperv1.DeleteKeyBundleResponse{}, nil
+}
+
+func (k *KeepServer) RegenerateRecoveryCodes(ctx context.Context,
+	req *gophkeeperv1.RegenerateRecoveryCodesRequest) (*gophkeeperv1.RegenerateRecoveryCodesResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recoveryCodes, err := k.keepService.RegenerateRecoveryCodes(ctx, uID, req.GetSrpProof(), req.GetPassword())
+	if err != nil {
+		return nil, k.rpcError(err, "regenerate recovery codes error")
+	}
+	return &gophkeeperv1.RegenerateRecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (k *KeepServer) RecoverAccount(ctx context.Context, req *gophkeeperv1.RecoverAccountRequest) (*gophkeeperv1.RecoverAccountResponse, error) {
+	user, remaining, err := k.keepService.RecoverAccount(ctx, req.GetLogin(), req.GetRecoveryCode(),
+		req.GetNewPassword(), req.GetNewSrpSalt(), req.GetNewSrpVerifier(), req.GetKeyBundles())
+	if err != nil {
+		return nil, k.rpcError(err, "recover account error")
+	}
+	if err := k.sendToken(ctx, user.UserID, user.TokenVersion); err != nil {
+		return nil, err
+	}
+	return &gophkeeperv1.RecoverAccountResponse{RemainingCodes: int32(remaining)}, nil
+}
+
+func (k *KeepServer) AddEmergencyContact(ctx context.Context,
+	req *gophkeeperv1.AddEmergencyContactRequest) (*gophkeeperv1.AddEmergencyContactResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contact, err := k.keepService.AddEmergencyContact(ctx, uID, req.GetSrpProof(), req.GetPassword(),
+		req.GetContactLogin(), req.GetWaitSeconds())
+	if err != nil {
+		return nil, k.rpcError(err, "add emergency contact error")
+	}
+	return &gophkeeperv1.AddEmergencyContactResponse{Contact: contact}, nil
+}
+
+func (k *KeepServer) RemoveEmergencyContact(ctx context.Context,
+	req *gophkeeperv1.RemoveEmergencyContactRequest) (*gophkeeperv1.RemoveEmergencyContactResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.keepService.RemoveEmergencyContact(ctx, uID, req.GetId()); err != nil {
+		return nil, k.rpcError(err, "remove emergency contact error")
+	}
+	return &gophkeeperv1.RemoveEmergencyContactResponse{}, nil
+}
+
+func (k *KeepServer) ListEmergencyContacts(ctx context.Context,
+	req *gophkeeperv1.ListEmergencyContactsRequest) (*gophkeeperv1.ListEmergencyContactsResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := k.keepService.ListEmergencyContacts(ctx, uID)
+	if err != nil {
+		return nil, k.rpcError(err, "list emergency contacts error")
+	}
+	return resp, nil
+}
+
+func (k *KeepServer) RequestEmergencyAccess(ctx context.Context,
+	req *gophkeeperv1.RequestEmergencyAccessRequest) (*gophkeeperv1.RequestEmergencyAccessResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contact, err := k.keepService.RequestEmergencyAccess(ctx, uID, req.GetId())
+	if err != nil {
+		return nil, k.rpcError(err, "request emergency access error")
+	}
+	return &gophkeeperv1.RequestEmergencyAccessResponse{Contact: contact}, nil
+}
+
+func (k *KeepServer) DenyEmergencyAccess(ctx context.Context,
+	req *gophkeeperv1.DenyEmergencyAccessRequest) (*gophkeeperv1.DenyEmergencyAccessResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.keepService.DenyEmergencyAccess(ctx, uID, req.GetId()); err != nil {
+		return nil, k.rpcError(err, "deny emergency access error")
+	}
+	return &gophkeeperv1.DenyEmergencyAccessResponse{}, nil
+}
+
+func (k *KeepServer) GetEmergencyVault(ctx context.Context,
+	req *gophkeeperv1.GetEmergencyVaultRequest) (*gophkeeperv1.GetEmergencyVaultResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := k.keepService.EmergencyVault(ctx, uID, req.GetId())
+	if err != nil {
+		return nil, k.rpcError(err, "get emergency vault error")
+	}
+	return resp, nil
+}
+
+func (k *KeepServer) ListAccountEvents(ctx context.Context,
+	req *gophkeeperv1.ListAccountEventsRequest) (*gophkeeperv1.ListAccountEventsResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	events, err := k.keepService.AccountEvents(ctx, uID, req.GetPageSize(), req.GetBeforeId())
+	if err != nil {
+		return nil, k.rpcError(err, "list account events error")
+	}
+	return &gophkeeperv1.ListAccountEventsResponse{Events: events}, nil
 }
 
 func (k *KeepServer) SyncDB(ctx context.Context, req *gophkeeperv1.SyncDBRequest) (*gophkeeperv1.SyncDBResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	itemsCount := items.Count(req)
+	if itemsCount > k.limits.MaxSyncItems {
+		k.zlog.Error().Int("items", itemsCount).Msg(errText.TooManySyncItemsError)
+		return nil, k.rpcErrorWith(ErrTooManySyncItems, "", map[string]string{
+			"limit": strconv.Itoa(k.limits.MaxSyncItems),
+		})
+	}
+	set, err := validation.SyncDB(req, uID)
+	if err != nil {
+		return nil, k.rpcError(err, "sync request parse error")
+	}
+	resp, err := k.keepService.SyncDB(req, set, uID, deviceID(ctx), idempotencyKey(ctx))
+	if err != nil {
+		return nil, k.rpcError(err, "sync error")
+	}
+	return resp, nil
+}
+
+func (k *KeepServer) ListItems(ctx context.Context, req *gophkeeperv1.ListItemsRequest) (*gophkeeperv1.ListItemsResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := k.keepService.ListItems(ctx, uID, req.GetFilter(), "",
+		req.GetPageSize(), req.GetPageToken(), req.GetIncludeSecrets())
+	if err != nil {
+		return nil, k.rpcError(err, "list items error")
+	}
+	return resp, nil
+}
+
+func (k *KeepServer) SearchItems(ctx context.Context, req *gophkeeperv1.SearchItemsRequest) (*gophkeeperv1.ListItemsResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := k.keepService.ListItems(ctx, uID, req.GetFilter(), req.GetQuery(),
+		req.GetPageSize(), req.GetPageToken(), req.GetIncludeSecrets())
+	if err != nil {
+		return nil, k.rpcError(err, "search items error")
+	}
+	return resp, nil
+}
+
+func (k *KeepServer) GetItem(ctx context.Context, req *gophkeeperv1.GetItemRequest) (*gophkeeperv1.GetItemResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := k.keepService.GetItem(ctx, uID, req.GetType(), req.GetId())
+	if err != nil {
+		return nil, k.rpcError(err, "get item error")
+	}
+	return resp, nil
+}
+
+func (k *KeepServer) ExportVault(ctx context.Context, req *gophkeeperv1.ExportVaultRequest) (*gophkeeperv1.ExportVaultResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := k.keepService.ExportVault(ctx, uID, req.GetPassphrase())
+	if err != nil {
+		return nil, k.rpcError(err, "export vault error")
+	}
+	return resp, nil
+}
+
+func (k *KeepServer) ImportVault(ctx context.Context, req *gophkeeperv1.ImportVaultRequest) (*gophkeeperv1.ImportVaultResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := k.keepService.ImportVault(ctx, uID, req)
+	if err != nil {
+		return nil, k.rpcError(err, "import vault error")
+	}
+	return resp, nil
+}
+
+func (k *KeepServer) ImportExternal(ctx context.Context, req *gophkeeperv1.ImportExternalRequest) (*gophkeeperv1.ImportExternalResponse, error) {
+	uID, err := k.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := k.keepService.ImportExternal(ctx, uID, req)
+	if err != nil {
+		return nil, k.rpcError(err, "external import error")
+	}
+	return resp, nil
+}
+
+func (k *KeepServer) WatchChanges(req *gophkeeperv1.WatchChangesRequest, stream gophkeeperv1.GophKeeper_WatchChangesServer) error {
+	uID, version, err := k.authToken(stream.Context())
+	if err != nil {
+		return err
+	}
+	err = k.keepService.WatchChanges(stream.Context(), uID, version, req.GetDeviceId(), stream.Send)
+	if err != nil {
+		k.zlog.Debug().Err(err).Msg("watch changes stream closed")
+		return k.rpcError(err, "watch changes error")
+	}
+	return nil
+}
+
+// deviceID - идентификатор устройства клиента из метаданных "device-id".
+func deviceID(ctx context.Context) string {
+	mData, _ := metadata.FromIncomingContext(ctx)
+	if values := mData.Get("device-id"); len(values) == 1 {
+		return values[0]
+	}
+	return ""
+}
+
+// idempotencyKey - ключ идемпотентности запроса из метаданных "idempotency-key".
+func idempotencyKey(ctx context.Context) string {
+	mData, _ := metadata.FromIncomingContext(ctx)
+	if values := mData.Get("idempotency-key"); len(values) == 1 {
+		return values[0]
+	}
+	return ""
+}
+
+// authUser - id пользователя из jwt токена в метаданных запроса.
+func (k *KeepServer) authUser(ctx context.Context) (int, error) {
+	uID, _, err := k.authToken(ctx)
+	return uID, err
+}
+
+// authToken - id пользователя и версия jwt токена из метаданных запроса.
+func (k *KeepServer) authToken(ctx context.Context) (int, int, error) {
 	mData, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		k.zlog.Error().Msg(errText.MetadataError)
-		return nil, status.Error(codes.PermissionDenied, errText.MetadataError)
+		return 0, 0, k.rpcError(ErrMissingMetadata, "")
 	}
 	values := mData.Get("Authorization")
 	if len(values) != 1 {
-		k.zlog.Error().Msg(errText.MissingAuthorizationKeyError)
-		return nil, status.Error(codes.PermissionDenied, errText.MissingAuthorizationKeyError)
+		return 0, 0, k.rpcError(ErrMissingAuthorization, "")
 	}
 	authToken := values[0]
-	userID, err := GetUID(authToken)
+	claims, err := parseToken(authToken, k.jwtCfg.Secret)
 	if err != nil {
-		if errors.Is(err, ErrInvalidToken) {
-			k.zlog.Error().Msg(err.Error())
-			return nil, status.Error(codes.PermissionDenied, err.Error())
-		}
-		return nil, status.Error(codes.Internal, "internal error")
+		// Любая ошибка разбора - поддельный, испорченный или просроченный токен.
+		k.zlog.Debug().Err(err).Msg("token rejected")
+		return 0, 0, k.rpcError(ErrInvalidToken, "")
 	}
-	k.zlog.Debug().Str("userId", userID).Msg("User id from token")
-	uID, err := strconv.Atoi(userID)
+	k.zlog.Debug().Str("userId", claims.UserID).Msg("User id from token")
+	uID, err := strconv.Atoi(claims.UserID)
 	if err != nil {
-		k.zlog.Error().Err(err).Msg("str to int error")
-		return nil, err
+		k.zlog.Debug().Err(err).Msg("token rejected")
+		return 0, 0, k.rpcError(ErrInvalidToken, "")
 	}
-	model, err := k.keepService.SyncDB(models.ProtoSyncModel{
-		Cards: req.Cards,
-		Texts: req.Texts,
-		Bins:  req.Bins,
-		Auth:  req.Auth,
-	}, uID)
-	if err != nil {
-		return nil, err
+	if err := k.keepService.CheckToken(ctx, uID, claims.TokenVersion); err != nil {
+		return 0, 0, k.rpcError(err, "check token error")
 	}
-	return &gophkeeperv1.SyncDBResponse{
-		Auth:  model.Auth,
-		Cards: model.Cards,
-		Texts: model.Texts,
-		Bins:  model.Bins,
-	}, nil
+	return uID, claims.TokenVersion, nil
 }
 
-func createJWTToken(uid int64) (string, error) {
+func (k *KeepServer) createJWTToken(uid int64, version int) (string, error) {
 	uuid := strconv.FormatInt(uid, 10)
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 3)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(k.jwtCfg.TTL)),
 		},
-		UserID: uuid,
+		UserID:       uuid,
+		TokenVersion: version,
 	})
 
-	tokenString, err := token.SignedString([]byte(SecretKey))
+	tokenString, err := token.SignedString([]byte(k.jwtCfg.Secret))
 	if err != nil {
 		return "", err
 	}
@@ -145,19 +509,27 @@ func createJWTToken(uid int64) (string, error) {
 }
 
 // GetUID - функция получения id пользвателя из jwt токена.
-func GetUID(tokenString string) (string, error) {
+func GetUID(tokenString string, secret string) (string, error) {
+	claim, err := parseToken(tokenString, secret)
+	if err != nil {
+		return "", err
+	}
+	return claim.UserID, nil
+}
+
+func parseToken(tokenString string, secret string) (*Claims, error) {
 	claim := &Claims{}
 
 	token, err := jwt.ParseWithClaims(tokenString, claim, func(t *jwt.Token) (interface{}, error) {
-		return []byte(SecretKey), nil
+		return []byte(secret), nil
 	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if !token.Valid {
-		return "", ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
 
-	return claim.UserID, nil
+	return claim, nil
 }
@@ -11,7 +11,7 @@ import (
 	"github.com/rs/zerolog/pkgerrors"
 )
 
-func SetupLogger(debug bool) *zerolog.Logger {
+func SetupLogger(debug bool, level string) *zerolog.Logger {
 	var zlog zerolog.Logger
 	if debug {
 		zerolog.TimestampFieldName = "Time"
@@ -55,6 +55,10 @@ func SetupLogger(debug bool) *zerolog.Logger {
 		file = short
 		return file + ":" + strconv.Itoa(line)
 	}
-	zlog = zerolog.New(os.Stdout).Level(zerolog.InfoLevel).With().Timestamp().Caller().Logger()
+	lvl, err := zerolog.ParseLevel(level)
+	if err != nil || level == "" {
+		lvl = zerolog.InfoLevel
+	}
+	zlog = zerolog.New(os.Stdout).Level(lvl).With().Timestamp().Caller().Logger()
 	return &zlog
 }
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+	"github.com/Dorrrke/GophKeeper-server/internal/storage"
+	gophkeeperv1 "github.com/Dorrrke/goph-keeper-proto/gen/go/gophkeeper"
+)
+
+var (
+	ErrTokenRevoked  = errors.New(errText.TokenRevokedError)
+	ErrEmptyPassword = errors.New(errText.EmptyPasswordError)
+	ErrEmptyLogin    = errors.New(errText.EmptyLoginError)
+	ErrLoginMismatch = errors.New(errText.LoginMismatchError)
+	ErrSamePassword  = errors.New(errText.SamePasswordError)
+	ErrAccountLocked = errors.New(errText.AccountLockedError)
+)
+
+// CheckToken - токен с версией version не отозван. Токены удаленного
+// пользователя и заблокированной учетной записи считаются отозванными.
+func (kp *KeepService) CheckToken(ctx context.Context, uID, version int) error {
+	current, locked, err := kp.stor.GetTokenVersion(ctx, uID)
+	if errors.Is(err, storage.ErrUserNotExist) {
+		return ErrTokenRevoked
+	}
+	if err != nil {
+		return err
+	}
+	if version != current || locked {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// reauth - повторная проверка пароля пользователя перед опасной операцией.
+// Клиент SRP не передает пароль на сервер и присылает доказательство proof
+// новой сессии входа, остальные клиенты - пароль. Доказательство или пароль
+// требуются для каждой операции.
+func (kp *KeepService) reauth(ctx context.Context, uID int, pass string, proof *gophkeeperv1.SrpProof) (models.UserModel, error) {
+	if proof.GetSessionId() != "" {
+		user, _, err := kp.checkSRPProof(ctx, proof.GetSessionId(), proof.GetM1())
+		if err != nil {
+			return models.UserModel{}, err
+		}
+		if int(user.UserID) != uID {
+			kp.log.Debug().Int("User ID", uID).Msg("SRP proof of another user")
+			return models.UserModel{}, ErrInvalidPassword
+		}
+		return user, nil
+	}
+	user, err := kp.stor.GetUserByID(ctx, uID)
+	if err != nil {
+		return models.UserModel{}, err
+	}
+	if pass == "" || !checkPassword(user, pass) {
+		kp.log.Debug().Int("User ID", uID).Msg("Re-authentication failed")
+		return models.UserModel{}, ErrInvalidPassword
+	}
+	return user, nil
+}
+
+// ChangePassword - смена мастер-пароля. Клиент SRP передает новые соль и
+// верификатор, остальные клиенты - новый пароль. Обертки ключа, зависящие
+// от пароля, заменяются вместе с ним. Все выданные токены отзываются;
+// возвращается пользователь с новой версией токенов.
+func (kp *KeepService) ChangePassword(ctx context.Context, uID int, proof *gophkeeperv1.SrpProof, current, pass string,
+	salt, verifier []byte, pBundles []*gophkeeperv1.KeyBundle) (models.UserModel, error) {
+	kp.log.Debug().Msg("called 'service.ChangePassword'")
+	bundles, err := keyBundlesFromProto(pBundles)
+	if err != nil {
+		return models.UserModel{}, err
+	}
+	if len(verifier) == 0 && pass != "" && pass == current {
+		return models.UserModel{}, ErrSamePassword
+	}
+	salt, verifier, err = newCredentials(pass, salt, verifier)
+	if err != nil {
+		return models.UserModel{}, err
+	}
+	user, err := kp.reauth(ctx, uID, current, proof)
+	if err != nil {
+		return models.UserModel{}, err
+	}
+	user.Hash, user.SRPSalt, user.SRPVerifier = "", salt, verifier
+	user.TokenVersion, err = kp.stor.ChangePassword(ctx, uID, salt, verifier, bundles)
+	if err != nil {
+		kp.log.Error().Err(err).Msg("Change password error")
+		return models.UserModel{}, err
+	}
+	return user, nil
+}
+
+// ChangeLogin - смена логина.
+func (kp *KeepService) ChangeLogin(ctx context.Context, uID int, proof *gophkeeperv1.SrpProof, pass, login string) error {
+	kp.log.Debug().Msg("called 'service.ChangeLogin'")
+	if login == "" {
+		return ErrEmptyLogin
+	}
+	if _, err := kp.reauth(ctx, uID, pass, proof); err != nil {
+		return err
+	}
+	return kp.stor.ChangeLogin(ctx, uID, login)
+}
+
+// DeleteAccount - удаление пользователя и всех его данных. login должен
+// совпадать с текущим логином пользователя.
+func (kp *KeepService) DeleteAccount(ctx context.Context, uID int, proof *gophkeeperv1.SrpProof, pass, login string) error {
+	kp.log.Debug().Msg("called 'service.DeleteAccount'")
+	user, err := kp.reauth(ctx, uID, pass, proof)
+	if err != nil {
+		return err
+	}
+	if login != user.Login {
+		return ErrLoginMismatch
+	}
+	if err := kp.stor.DeleteUser(ctx, uID); err != nil {
+		kp.log.Error().Err(err).Msg("Delete account error")
+		return err
+	}
+	kp.log.Info().Int("User ID", uID).Msg("Account deleted")
+	return nil
+}
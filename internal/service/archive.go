@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/archive"
+	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+	gophkeeperv1 "github.com/Dorrrke/goph-keeper-proto/gen/go/gophkeeper"
+)
+
+// maxArchiveContent - предельный размер распакованного содержимого архива.
+const maxArchiveContent = 256 << 20
+
+var ErrUnknownDuplicatePolicy = errors.New(errText.UnknownDuplicatePolicyError)
+
+var duplicatePolicies = map[gophkeeperv1.DuplicatePolicy]archive.DuplicatePolicy{
+	gophkeeperv1.DuplicatePolicy_DUPLICATE_POLICY_SKIP:      archive.Skip,
+	gophkeeperv1.DuplicatePolicy_DUPLICATE_POLICY_OVERWRITE: archive.Overwrite,
+	gophkeeperv1.DuplicatePolicy_DUPLICATE_POLICY_KEEP_BOTH: archive.KeepBoth,
+}
+
+// ExportVault - зашифрованный архив всех записей пользователя.
+func (kp *KeepService) ExportVault(ctx context.Context, uID int, passphrase string) (*gophkeeperv1.ExportVaultResponse, error) {
+	kp.log.Debug().Msg("called 'service.ExportVault'")
+	if len(passphrase) < archive.MinPassphraseLen {
+		return nil, archive.ErrWeakPassphrase
+	}
+	set, err := kp.stor.ExportItems(ctx, uID)
+	if err != nil {
+		return nil, err
+	}
+	vault := archive.FromItems(set)
+	data, err := archive.Seal(vault, []byte(passphrase))
+	if err != nil {
+		kp.log.Error().Err(err).Msg("Seal vault archive error")
+		return nil, err
+	}
+	return &gophkeeperv1.ExportVaultResponse{
+		Archive: data,
+		Items:   int32(len(vault.Items)),
+	}, nil
+}
+
+// ImportVault - слияние записей из архива с хранилищем пользователя.
+func (kp *KeepService) ImportVault(ctx context.Context, uID int, req *gophkeeperv1.ImportVaultRequest) (*gophkeeperv1.ImportVaultResponse, error) {
+	kp.log.Debug().Msg("called 'service.ImportVault'")
+	policy, ok := duplicatePolicies[req.GetOnDuplicate()]
+	if !ok {
+		return nil, ErrUnknownDuplicatePolicy
+	}
+	vault, err := archive.Open(req.GetArchive(), []byte(req.GetPassphrase()), maxArchiveContent)
+	if err != nil {
+		return nil, err
+	}
+	set, err := vault.ToItems(uID)
+	if err != nil {
+		return nil, err
+	}
+	res, err := kp.stor.ImportItems(ctx, uID, set, policy)
+	if err != nil {
+		return nil, err
+	}
+	kp.log.Debug().Int("created", res.Created).Int("updated", res.Updated).Int("skipped", res.Skipped).Msg("Vault imported")
+	return &gophkeeperv1.ImportVaultResponse{
+		Created: int32(res.Created),
+		Updated: int32(res.Updated),
+		Skipped: int32(res.Skipped),
+	}, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/events"
+	gophkeeperv1 "github.com/Dorrrke/goph-keeper-proto/gen/go/gophkeeper"
+)
+
+// tokenCheckInterval - период повторной проверки токена открытого потока
+// изменений, если изменений нет.
+const tokenCheckInterval = time.Minute
+
+// WatchChanges - отправка изменений хранилища, сделанных другими устройствами,
+// до отмены ctx или ошибки send. Токен с версией version проверяется перед
+// каждой отправкой и раз в tokenCheckInterval: после блокировки учетной
+// записи, смены пароля или отзыва токенов поток завершается ErrTokenRevoked.
+func (kp *KeepService) WatchChanges(ctx context.Context, uID, version int, device string,
+	send func(*gophkeeperv1.WatchChangesResponse) error) error {
+	kp.log.Debug().Int("User ID", uID).Str("device", device).Msg("called 'service.WatchChanges'")
+	sub := kp.stor.Subscribe(uID, device)
+	defer sub.Close()
+	ticker := time.NewTicker(tokenCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := kp.CheckToken(ctx, uID, version); err != nil {
+				return err
+			}
+		case b := <-sub.C:
+			if err := kp.CheckToken(ctx, uID, version); err != nil {
+				return err
+			}
+			if err := send(batchToProto(b)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func batchToProto(b events.Batch) *gophkeeperv1.WatchChangesResponse {
+	resp := &gophkeeperv1.WatchChangesResponse{Revision: b.Revision, Resync: b.Resync}
+	for _, c := range b.Changes {
+		resp.Events = append(resp.Events, &gophkeeperv1.ChangeEvent{
+			Type:     c.Type,
+			Id:       c.ID,
+			Revision: b.Revision,
+			Deleted:  c.Deleted,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/importer"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+	gophkeeperv1 "github.com/Dorrrke/goph-keeper-proto/gen/go/gophkeeper"
+)
+
+var ErrUnknownImportFormat = errors.New(errText.UnknownImportFormatError)
+
+// ImportExternal - импорт экспорта другого менеджера паролей. Пропущенные
+// записи возвращаются клиенту с причиной.
+func (kp *KeepService) ImportExternal(ctx context.Context, uID int, req *gophkeeperv1.ImportExternalRequest) (*gophkeeperv1.ImportExternalResponse, error) {
+	kp.log.Debug().Msg("called 'service.ImportExternal'")
+	policy, ok := duplicatePolicies[req.GetOnDuplicate()]
+	if !ok {
+		return nil, ErrUnknownDuplicatePolicy
+	}
+
+	var (
+		res importer.Result
+		err error
+	)
+	switch req.GetFormat() {
+	case gophkeeperv1.ImportFormat_IMPORT_FORMAT_KEEPASS_XML:
+		res, err = importer.KeePass(req.GetData())
+	case gophkeeperv1.ImportFormat_IMPORT_FORMAT_BITWARDEN_JSON:
+		res, err = importer.Bitwarden(req.GetData())
+	case gophkeeperv1.ImportFormat_IMPORT_FORMAT_CSV:
+		var mapping importer.CSVMapping
+		mapping, err = csvMappingFromProto(req.GetCsv())
+		if err == nil {
+			res, err = importer.CSV(req.GetData(), mapping)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownImportFormat, req.GetFormat())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := kp.stor.ImportItems(ctx, uID, res.Items, policy)
+	if err != nil {
+		return nil, err
+	}
+	kp.log.Debug().Int("created", stored.Created).Int("skipped", len(res.Skipped)).Msg("External import done")
+	resp := &gophkeeperv1.ImportExternalResponse{
+		Created:    int32(stored.Created),
+		Updated:    int32(stored.Updated),
+		Duplicates: int32(stored.Skipped),
+	}
+	for _, s := range res.Skipped {
+		resp.Skipped = append(resp.Skipped, &gophkeeperv1.SkippedEntry{Entry: s.Entry, Reason: s.Reason})
+	}
+	return resp, nil
+}
+
+func csvMappingFromProto(p *gophkeeperv1.CsvMapping) (importer.CSVMapping, error) {
+	t, ok := items.Lookup(p.GetType())
+	if !ok {
+		return importer.CSVMapping{}, fmt.Errorf("%w: %q", ErrUnknownItemType, p.GetType())
+	}
+	mapping := importer.CSVMapping{Type: t, Columns: p.GetColumns()}
+	if d := p.GetDelimiter(); d != "" {
+		r, size := utf8.DecodeRuneInString(d)
+		if size != len(d) {
+			return importer.CSVMapping{}, fmt.Errorf("%w: delimiter must be a single character", importer.ErrInvalidMapping)
+		}
+		mapping.Delimiter = r
+	}
+	return mapping, nil
+}
@@ -3,10 +3,13 @@ package service
 import (
 	"context"
 	"errors"
-	"strconv"
 
+	"github.com/Dorrrke/GophKeeper-server/internal/config"
 	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
 	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/recovery"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/srp"
 	"github.com/Dorrrke/GophKeeper-server/internal/storage"
 	gophkeeperv1 "github.com/Dorrrke/goph-keeper-proto/gen/go/gophkeeper"
 	"github.com/rs/zerolog"
@@ -16,173 +19,121 @@ import (
 var ErrInvalidPassword = errors.New(errText.InvalidPasswordError)
 
 type KeepService struct {
-	stor storage.KeepStorage
-	log  *zerolog.Logger
+	stor     storage.KeepStorage
+	recovery config.RecoveryConfig
+	limits   config.LimitsConfig
+	decoys   decoys
+	log      *zerolog.Logger
 }
 
-func New(stor storage.KeepStorage, zlog *zerolog.Logger) *KeepService {
+func New(stor storage.KeepStorage, recovery config.RecoveryConfig, limits config.LimitsConfig, srpCfg config.SRPConfig, zlog *zerolog.Logger) *KeepService {
 	return &KeepService{
-		stor: stor,
-		log:  zlog,
+		stor:     stor,
+		recovery: recovery,
+		limits:   limits,
+		decoys:   newDecoys([]byte(srpCfg.DecoyKey)),
+		log:      zlog,
 	}
 }
 
-func (kp *KeepService) RegisterUser(login string, pass string) (int64, error) {
+// RegisterUser - регистрация клиента без поддержки SRP: сервер сам вычисляет
+// соль и верификатор из пароля и не хранит bcrypt-хеш. Возвращает также
+// коды восстановления.
+func (kp *KeepService) RegisterUser(login string, pass string) (int64, []string, error) {
 	kp.log.Debug().Msg("called 'service.RegisterUser'")
-	hash, err := hashPass(pass)
+	salt, verifier, err := newVerifier(pass)
 	if err != nil {
-		kp.log.Error().Err(err).Msg("Password hashing error ")
-		return -1, err
+		kp.log.Error().Err(err).Msg("Verifier generation error")
+		return -1, nil, err
 	}
-	uid, err := kp.stor.SaveUser(context.Background(), models.UserModel{
-		Login: login,
-		Hash:  hash,
+	return kp.saveUser(context.Background(), models.UserModel{
+		Login:       login,
+		SRPSalt:     salt,
+		SRPVerifier: verifier,
 	})
+}
+
+// saveUser - создание пользователя с новыми кодами восстановления.
+func (kp *KeepService) saveUser(ctx context.Context, user models.UserModel) (int64, []string, error) {
+	codes, err := recovery.NewCodes(kp.recovery.Codes)
+	if err != nil {
+		return -1, nil, err
+	}
+	uid, err := kp.stor.SaveUser(ctx, user, recovery.HashCodes(codes))
 	if err != nil {
 		kp.log.Error().Err(err).Msg("Error when saving a user")
-		return -1, err
+		return -1, nil, err
 	}
-	return uid, nil
+	return uid, codes, nil
 }
 
+// LoginUser - вход по паролю для клиентов без поддержки SRP. Учетная запись
+// с bcrypt-хешем после успешного входа переводится на SRP.
 func (kp *KeepService) LoginUser(login string, pass string) (models.UserModel, error) {
 	kp.log.Debug().Msg("called 'service.LoginUser'")
-	uID, hashFromDB, err := kp.stor.GetUserHash(context.Background(), login)
+	ctx := context.Background()
+	user, err := kp.stor.GetUser(ctx, login)
+	if errors.Is(err, storage.ErrUserNotExist) {
+		// Ответ не раскрывает, зарегистрирован ли логин: пароль проверяется
+		// по подставному верификатору, ошибка та же, что при неверном пароле.
+		salt, verifier := kp.decoys.get(login)
+		srp.CheckPassword(salt, verifier, pass)
+		return models.UserModel{}, ErrInvalidPassword
+	}
 	if err != nil {
 		kp.log.Error().Err(err).Msg("Getting user password hash from db error")
 		return models.UserModel{}, err
 	}
-	if !matchPass(pass, hashFromDB) {
+	if !checkPassword(user, pass) {
 		kp.log.Debug().Msg("Entered password and hash do not match")
 		return models.UserModel{}, ErrInvalidPassword
 	}
-	return models.UserModel{
-		UserID: uID,
-		Login:  login,
-		Hash:   pass,
-	}, nil
-}
-
-func (kp *KeepService) SyncDB(pModel models.ProtoSyncModel, uID int) (models.ProtoSyncModel, error) {
-	sModel, err := protoModelToModel(pModel, uID)
-	if err != nil {
-		return models.ProtoSyncModel{}, err
-	}
-	res, err := kp.stor.SyncDB(context.Background(), sModel, uID)
-	if err != nil {
-		return models.ProtoSyncModel{}, err
-	}
-	if err = kp.stor.ClearDB(context.Background(), uID); err != nil {
-		return models.ProtoSyncModel{}, err
-	}
-
-	return modelToProtoModel(res), nil
-}
-
-func modelToProtoModel(model models.SyncModel) models.ProtoSyncModel {
-	var pModel models.ProtoSyncModel
-	for _, data := range model.Bins {
-		bin := gophkeeperv1.SyncBinData{
-			Name:    data.Name,
-			Data:    data.Data,
-			Deleted: data.Deleted,
-			Updated: data.Updated,
-		}
-		pModel.Bins = append(pModel.Bins, &bin)
-	}
-	for _, data := range model.Auth {
-		auth := gophkeeperv1.SyncAuth{
-			Name:     data.Name,
-			Login:    data.Login,
-			Password: data.Password,
-			Deleted:  data.Deleted,
-			Updated:  data.Updated,
-		}
-		pModel.Auth = append(pModel.Auth, &auth)
+	if user.Locked {
+		return models.UserModel{}, ErrAccountLocked
 	}
-	for _, data := range model.Cards {
-		card := gophkeeperv1.SyncCard{
-			Name:    data.Name,
-			Number:  data.Number,
-			Date:    data.Date,
-			Cvv:     strconv.Itoa(data.CVVCode),
-			Deleted: data.Deleted,
-			Updated: data.Updated,
+	if len(user.SRPVerifier) == 0 {
+		salt, verifier, err := newVerifier(pass)
+		if err == nil {
+			err = kp.stor.UpgradeToSRP(ctx, int(user.UserID), salt, verifier)
 		}
-		pModel.Cards = append(pModel.Cards, &card)
-	}
-	for _, data := range model.Texts {
-		text := gophkeeperv1.SyncText{
-			Name:    data.Name,
-			Data:    data.Data,
-			Deleted: data.Deleted,
-			Updated: data.Updated,
+		if err != nil {
+			// Вход не прерывается: перевод повторится при следующем входе.
+			kp.log.Error().Err(err).Int64("User ID", user.UserID).Msg("Upgrade to srp error")
 		}
-		pModel.Texts = append(pModel.Texts, &text)
 	}
-
-	return pModel
+	kp.touchLogin(ctx, int(user.UserID))
+	return user, nil
 }
 
-func protoModelToModel(model models.ProtoSyncModel, uID int) (models.SyncModel, error) {
-	var sModel models.SyncModel
-	for _, data := range model.Bins {
-		bin := models.SyncBinaryDataModel{
-			UserID:  uID,
-			Name:    data.Name,
-			Data:    data.Data,
-			Deleted: data.Deleted,
-			Updated: data.Updated,
-		}
-		sModel.Bins = append(sModel.Bins, bin)
-	}
-	for _, data := range model.Auth {
-		auth := models.SyncLoginModel{
-			UserID:   uID,
-			Name:     data.Name,
-			Login:    data.Login,
-			Password: data.Password,
-			Deleted:  data.Deleted,
-			Updated:  data.Updated,
-		}
-		sModel.Auth = append(sModel.Auth, auth)
-	}
-	for _, data := range model.Cards {
-		cvv, err := strconv.Atoi(data.Cvv)
-		if err != nil {
-			return models.SyncModel{}, err
-		}
-		card := models.SyncCardModel{
-			UserID:  uID,
-			Name:    data.Name,
-			Number:  data.Number,
-			Date:    data.Date,
-			CVVCode: cvv,
-			Deleted: data.Deleted,
-			Updated: data.Updated,
-		}
-		sModel.Cards = append(sModel.Cards, card)
-	}
-	for _, data := range model.Texts {
-		text := models.SyncTextDataModel{
-			UserID:  uID,
-			Name:    data.Name,
-			Data:    data.Data,
-			Deleted: data.Deleted,
-			Updated: data.Updated,
-		}
-		sModel.Texts = append(sModel.Texts, text)
+// touchLogin - отметка о входе; ошибка не прерывает вход.
+func (kp *KeepService) touchLogin(ctx context.Context, uID int) {
+	if err := kp.stor.TouchLogin(ctx, uID); err != nil {
+		kp.log.Error().Err(err).Int("User ID", uID).Msg("Update last login error")
 	}
+}
 
-	return sModel, nil
+// SyncDB - синхронизация записей клиента; set - записи req, уже
+// проверенные при разборе запроса. Если задан ключ идемпотентности key,
+// ответ сохраняется, и повтор запроса с тем же ключом получает его
+// без повторной синхронизации. Запрос с токеном страницы возвращает
+// следующую страницу ответа предыдущей синхронизации.
+func (kp *KeepService) SyncDB(req *gophkeeperv1.SyncDBRequest, set items.Set, uID int, device, key string) (*gophkeeperv1.SyncDBResponse, error) {
+	if req.GetPageToken() != "" {
+		return kp.syncPage(req, uID)
+	}
+	if key == "" {
+		return kp.syncDB(set, uID, device, kp.syncPaging(req), nil)
+	}
+	return kp.idempotentSync(req, set, uID, device, key)
 }
 
-func hashPass(pass string) (string, error) {
-	hashedPass, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.MinCost)
+func (kp *KeepService) syncDB(set items.Set, uID int, device string, paging storage.SyncPaging,
+	req *storage.SyncRequest) (*gophkeeperv1.SyncDBResponse, error) {
+	page, cursor, err := kp.stor.SyncDB(context.Background(), set, uID, device, paging, req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return string(hashedPass), nil
+	return syncResponse(page, cursor), nil
 }
 
 func matchPass(pass string, hashFromDB string) bool {
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+	gophkeeperv1 "github.com/Dorrrke/goph-keeper-proto/gen/go/gophkeeper"
+)
+
+const (
+	maxKeyBundleNameLen = 50
+	// maxWrappedKeySize - предел размера обертки и ее параметров.
+	maxWrappedKeySize = 4096
+)
+
+var ErrInvalidKeyBundle = errors.New(errText.InvalidKeyBundleError)
+
+// KeyBundles - обернутые ключи пользователя.
+func (kp *KeepService) KeyBundles(ctx context.Context, uID int) ([]*gophkeeperv1.KeyBundle, error) {
+	bundles, err := kp.stor.KeyBundles(ctx, uID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*gophkeeperv1.KeyBundle, 0, len(bundles))
+	for _, b := range bundles {
+		res = append(res, keyBundleToProto(b))
+	}
+	return res, nil
+}
+
+// PutKeyBundle - создание или замена обертки ключа.
+func (kp *KeepService) PutKeyBundle(ctx context.Context, uID int, p *gophkeeperv1.KeyBundle) (*gophkeeperv1.KeyBundle, error) {
+	kp.log.Debug().Msg("called 'service.PutKeyBundle'")
+	b, err := keyBundleFromProto(p)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := kp.stor.PutKeyBundle(ctx, uID, b)
+	if err != nil {
+		return nil, err
+	}
+	return keyBundleToProto(stored), nil
+}
+
+// DeleteKeyBundle - удаление обертки ключа по имени.
+func (kp *KeepService) DeleteKeyBundle(ctx context.Context, uID int, name string) error {
+	kp.log.Debug().Msg("called 'service.DeleteKeyBundle'")
+	return kp.stor.DeleteKeyBundle(ctx, uID, name)
+}
+
+func keyBundlesFromProto(list []*gophkeeperv1.KeyBundle) ([]models.KeyBundle, error) {
+	res := make([]models.KeyBundle, 0, len(list))
+	for _, p := range list {
+		b, err := keyBundleFromProto(p)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, b)
+	}
+	return res, nil
+}
+
+func keyBundleFromProto(p *gophkeeperv1.KeyBundle) (models.KeyBundle, error) {
+	b := models.KeyBundle{
+		Name:       p.GetName(),
+		Algorithm:  p.GetAlgorithm(),
+		WrappedKey: p.GetWrappedKey(),
+		Params:     p.GetParams(),
+	}
+	switch {
+	case b.Name == "" || utf8.RuneCountInString(b.Name) > maxKeyBundleNameLen:
+		return b, fmt.Errorf("%w: name must be 1-%d characters", ErrInvalidKeyBundle, maxKeyBundleNameLen)
+	case b.Algorithm == "" || utf8.RuneCountInString(b.Algorithm) > maxKeyBundleNameLen:
+		return b, fmt.Errorf("%w: algorithm must be 1-%d characters", ErrInvalidKeyBundle, maxKeyBundleNameLen)
+	case len(b.WrappedKey) == 0 || len(b.WrappedKey) > maxWrappedKeySize:
+		return b, fmt.Errorf("%w: wrapped key must be 1-%d bytes", ErrInvalidKeyBundle, maxWrappedKeySize)
+	case len(b.Params) > maxWrappedKeySize:
+		return b, fmt.Errorf("%w: params must be at most %d bytes", ErrInvalidKeyBundle, maxWrappedKeySize)
+	}
+	return b, nil
+}
+
+func keyBundleToProto(b models.KeyBundle) *gophkeeperv1.KeyBundle {
+	return &gophkeeperv1.KeyBundle{
+		Name:       b.Name,
+		Algorithm:  b.Algorithm,
+		WrappedKey: b.WrappedKey,
+		Params:     b.Params,
+		Updated:    b.Updated.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+	gophkeeperv1 "github.com/Dorrrke/goph-keeper-proto/gen/go/gophkeeper"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownItemType  = errors.New(errText.UnknownItemTypeError)
+	ErrInvalidPageToken = errors.New(errText.InvalidPageTokenError)
+	ErrInvalidFilter    = errors.New(errText.InvalidFilterError)
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// ListItems - страница метаданных записей по фильтру. Строка поиска query
+// сравнивается с именем и тегами записей.
+func (kp *KeepService) ListItems(ctx context.Context, uID int, pFilter *gophkeeperv1.ItemFilter, query string,
+	pageSize int32, pageToken string, withSecrets bool) (*gophkeeperv1.ListItemsResponse, error) {
+	kp.log.Debug().Msg("called 'service.ListItems'")
+	filter, err := filterFromProto(pFilter)
+	if err != nil {
+		return nil, err
+	}
+	filter.Query = query
+
+	after, err := decodePageToken(pageToken)
+	if err != nil {
+		return nil, err
+	}
+	limit := int(pageSize)
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	// Лишняя запись показывает, есть ли следующая страница.
+	metas, full, err := kp.stor.ListItems(ctx, uID, filter, after, limit+1, withSecrets)
+	if err != nil {
+		return nil, err
+	}
+	resp := &gophkeeperv1.ListItemsResponse{}
+	if len(metas) > limit {
+		metas = metas[:limit]
+		resp.NextPageToken = encodePageToken(metas[limit-1].Cursor())
+	}
+	for _, meta := range metas {
+		pMeta := items.MetaToProto(meta)
+		if it, ok := full[meta.ID]; ok {
+			t, _ := items.Lookup(meta.Type)
+			pMeta.Item = t.ItemToProto(it)
+		}
+		resp.Items = append(resp.Items, pMeta)
+	}
+	return resp, nil
+}
+
+// GetItem - запись целиком, включая секретные поля.
+func (kp *KeepService) GetItem(ctx context.Context, uID int, typeName, id string) (*gophkeeperv1.GetItemResponse, error) {
+	kp.log.Debug().Msg("called 'service.GetItem'")
+	t, ok := items.Lookup(typeName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownItemType, typeName)
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("%w: invalid id %q", ErrInvalidFilter, id)
+	}
+	it, err := kp.stor.GetItem(ctx, t, uID, id)
+	if err != nil {
+		return nil, err
+	}
+	return &gophkeeperv1.GetItemResponse{Item: t.ItemToProto(it)}, nil
+}
+
+func filterFromProto(p *gophkeeperv1.ItemFilter) (items.Filter, error) {
+	f := items.Filter{
+		NamePrefix:   p.GetNamePrefix(),
+		NameContains: p.GetNameContains(),
+		Tag:          p.GetTag(),
+		FolderID:     p.GetFolderId(),
+		WithDeleted:  p.GetIncludeDeleted(),
+	}
+	for _, name := range p.GetTypes() {
+		t, ok := items.Lookup(name)
+		if !ok {
+			return items.Filter{}, fmt.Errorf("%w: %q", ErrUnknownItemType, name)
+		}
+		f.Types = append(f.Types, t)
+	}
+	if len(f.Types) == 0 {
+		for _, t := range items.Types() {
+			if t.Meta {
+				f.Types = append(f.Types, t)
+			}
+		}
+	}
+	if f.FolderID != "" {
+		if _, err := uuid.Parse(f.FolderID); err != nil {
+			return items.Filter{}, fmt.Errorf("%w: invalid folder id %q", ErrInvalidFilter, f.FolderID)
+		}
+	}
+	if since := p.GetModifiedSince(); since != "" {
+		ts, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return items.Filter{}, fmt.Errorf("%w: invalid modified_since %q", ErrInvalidFilter, since)
+		}
+		f.ModifiedSince = ts
+	}
+	return f, nil
+}
+
+func encodePageToken(c items.Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodePageToken(token string) (*items.Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	var c items.Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	return &c, nil
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/recovery"
+	"github.com/Dorrrke/GophKeeper-server/internal/storage"
+	gophkeeperv1 "github.com/Dorrrke/goph-keeper-proto/gen/go/gophkeeper"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultEventsPageSize = 50
+	maxEventsPageSize     = 500
+)
+
+var (
+	ErrSelfEmergencyContact = errors.New(errText.SelfEmergencyContactError)
+	ErrInvalidEmergencyWait = errors.New(errText.InvalidEmergencyWaitError)
+)
+
+// RegenerateRecoveryCodes - новые коды восстановления; прежние перестают
+// действовать.
+func (kp *KeepService) RegenerateRecoveryCodes(ctx context.Context, uID int, proof *gophkeeperv1.SrpProof, pass string) ([]string, error) {
+	kp.log.Debug().Msg("called 'service.RegenerateRecoveryCodes'")
+	if _, err := kp.reauth(ctx, uID, pass, proof); err != nil {
+		return nil, err
+	}
+	codes, err := recovery.NewCodes(kp.recovery.Codes)
+	if err != nil {
+		return nil, err
+	}
+	if err := kp.stor.ReplaceRecoveryCodes(ctx, uID, recovery.HashCodes(codes)); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// RecoverAccount - смена забытого пароля по коду восстановления. Обертки
+// ключа, зависящие от пароля, заменяются вместе с ним. Возвращает
+// пользователя с новой версией токенов и число оставшихся кодов.
+func (kp *KeepService) RecoverAccount(ctx context.Context, login, code, pass string, salt, verifier []byte,
+	pBundles []*gophkeeperv1.KeyBundle) (models.UserModel, int, error) {
+	kp.log.Debug().Msg("called 'service.RecoverAccount'")
+	bundles, err := keyBundlesFromProto(pBundles)
+	if err != nil {
+		return models.UserModel{}, 0, err
+	}
+	salt, verifier, err = newCredentials(pass, salt, verifier)
+	if err != nil {
+		return models.UserModel{}, 0, err
+	}
+	user, err := kp.stor.GetUser(ctx, login)
+	if errors.Is(err, storage.ErrUserNotExist) {
+		// Ответ не раскрывает, зарегистрирован ли логин.
+		return models.UserModel{}, 0, storage.ErrInvalidRecoveryCode
+	}
+	if err != nil {
+		return models.UserModel{}, 0, err
+	}
+	if user.Locked {
+		return models.UserModel{}, 0, ErrAccountLocked
+	}
+	uID := int(user.UserID)
+	version, remaining, err := kp.stor.RecoverAccount(ctx, uID, recovery.HashCode(code), salt, verifier, bundles)
+	if err != nil {
+		if !errors.Is(err, storage.ErrInvalidRecoveryCode) {
+			kp.log.Error().Err(err).Msg("Recover account error")
+		}
+		return models.UserModel{}, 0, err
+	}
+	kp.log.Info().Int("User ID", uID).Int("remaining", remaining).Msg("Account recovered with recovery code")
+	user.Hash, user.SRPSalt, user.SRPVerifier, user.TokenVersion = "", salt, verifier, version
+	return user, remaining, nil
+}
+
+// AddEmergencyContact - добавление доверенного контакта. waitSeconds = 0
+// означает время ожидания по умолчанию.
+func (kp *KeepService) AddEmergencyContact(ctx context.Context, uID int, proof *gophkeeperv1.SrpProof, pass, contactLogin string,
+	waitSeconds int64) (*gophkeeperv1.EmergencyContact, error) {
+	kp.log.Debug().Msg("called 'service.AddEmergencyContact'")
+	wait := kp.recovery.EmergencyDefaultWait
+	if waitSeconds != 0 {
+		wait = time.Duration(waitSeconds) * time.Second
+		if waitSeconds < 0 || wait < kp.recovery.EmergencyMinWait || wait > kp.recovery.EmergencyMaxWait {
+			return nil, ErrInvalidEmergencyWait
+		}
+	}
+	user, err := kp.reauth(ctx, uID, pass, proof)
+	if err != nil {
+		return nil, err
+	}
+	contact, err := kp.stor.GetUser(ctx, contactLogin)
+	if errors.Is(err, storage.ErrUserNotExist) {
+		return nil, storage.ErrEmergencyContactNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	if contact.UserID == user.UserID {
+		return nil, ErrSelfEmergencyContact
+	}
+	res, err := kp.stor.AddEmergencyContact(ctx, uID, int(contact.UserID), int64(wait/time.Second))
+	if err != nil {
+		return nil, err
+	}
+	return emergencyContactToProto(res), nil
+}
+
+// RemoveEmergencyContact - удаление контакта владельцем.
+func (kp *KeepService) RemoveEmergencyContact(ctx context.Context, uID int, id string) error {
+	kp.log.Debug().Msg("called 'service.RemoveEmergencyContact'")
+	if _, err := uuid.Parse(id); err != nil {
+		return storage.ErrEmergencyContactNotExist
+	}
+	return kp.stor.RemoveEmergencyContact(ctx, uID, id)
+}
+
+// ListEmergencyContacts - контакты пользователя и владельцы, указавшие его
+// контактом.
+func (kp *KeepService) ListEmergencyContacts(ctx context.Context, uID int) (*gophkeeperv1.ListEmergencyContactsResponse, error) {
+	list, err := kp.stor.EmergencyContacts(ctx, uID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &gophkeeperv1.ListEmergencyContactsResponse{}
+	for _, c := range list {
+		if c.OwnerID == int64(uID) {
+			resp.Contacts = append(resp.Contacts, emergencyContactToProto(c))
+		} else {
+			resp.Grantors = append(resp.Grantors, emergencyContactToProto(c))
+		}
+	}
+	return resp, nil
+}
+
+// RequestEmergencyAccess - запрос контакта на доступ к хранилищу владельца.
+func (kp *KeepService) RequestEmergencyAccess(ctx context.Context, uID int, id string) (*gophkeeperv1.EmergencyContact, error) {
+	kp.log.Debug().Msg("called 'service.RequestEmergencyAccess'")
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, storage.ErrEmergencyContactNotExist
+	}
+	contact, err := kp.stor.RequestEmergencyAccess(ctx, uID, id)
+	if err != nil {
+		return nil, err
+	}
+	return emergencyContactToProto(contact), nil
+}
+
+// DenyEmergencyAccess - отклонение запроса доступа владельцем.
+func (kp *KeepService) DenyEmergencyAccess(ctx context.Context, uID int, id string) error {
+	kp.log.Debug().Msg("called 'service.DenyEmergencyAccess'")
+	if _, err := uuid.Parse(id); err != nil {
+		return storage.ErrEmergencyContactNotExist
+	}
+	return kp.stor.DenyEmergencyAccess(ctx, uID, id)
+}
+
+// EmergencyVault - записи владельца и обертки ключа для контакта, которому
+// открыт доступ.
+func (kp *KeepService) EmergencyVault(ctx context.Context, uID int, id string) (*gophkeeperv1.GetEmergencyVaultResponse, error) {
+	kp.log.Debug().Msg("called 'service.EmergencyVault'")
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, storage.ErrEmergencyContactNotExist
+	}
+	set, bundles, err := kp.stor.EmergencyVault(ctx, uID, id)
+	if err != nil {
+		return nil, err
+	}
+	resp := &gophkeeperv1.GetEmergencyVaultResponse{Vault: &gophkeeperv1.SyncDBResponse{}}
+	set.ToProto(resp.Vault)
+	for _, b := range bundles {
+		resp.KeyBundles = append(resp.KeyBundles, keyBundleToProto(b))
+	}
+	return resp, nil
+}
+
+// AccountEvents - страница журнала событий, от новых к старым.
+func (kp *KeepService) AccountEvents(ctx context.Context, uID int, pageSize int32, beforeID int64) ([]*gophkeeperv1.AccountEvent, error) {
+	limit := int(pageSize)
+	if limit <= 0 {
+		limit = defaultEventsPageSize
+	}
+	if limit > maxEventsPageSize {
+		limit = maxEventsPageSize
+	}
+	list, err := kp.stor.AccountEvents(ctx, uID, beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*gophkeeperv1.AccountEvent, 0, len(list))
+	for _, e := range list {
+		res = append(res, &gophkeeperv1.AccountEvent{
+			Id:         e.ID,
+			Event:      e.Event,
+			ActorLogin: e.ActorLogin,
+			Details:    e.Details,
+			Created:    e.Created.UTC().Format(time.RFC3339),
+		})
+	}
+	return res, nil
+}
+
+func emergencyContactToProto(c models.EmergencyContact) *gophkeeperv1.EmergencyContact {
+	p := &gophkeeperv1.EmergencyContact{
+		Id:           c.ID,
+		OwnerLogin:   c.OwnerLogin,
+		ContactLogin: c.ContactLogin,
+		WaitSeconds:  c.WaitSeconds,
+		Status:       gophkeeperv1.EmergencyStatus_EMERGENCY_STATUS_IDLE,
+	}
+	if c.RequestedAt != nil {
+		p.Status = gophkeeperv1.EmergencyStatus_EMERGENCY_STATUS_PENDING
+		if c.Granted {
+			p.Status = gophkeeperv1.EmergencyStatus_EMERGENCY_STATUS_GRANTED
+		}
+		p.RequestedAt = c.RequestedAt.UTC().Format(time.RFC3339)
+		p.AccessAt = c.RequestedAt.Add(time.Duration(c.WaitSeconds) * time.Second).UTC().Format(time.RFC3339)
+	}
+	return p
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"time"
+
+	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/srp"
+	"github.com/Dorrrke/GophKeeper-server/internal/storage"
+	"github.com/google/uuid"
+)
+
+const (
+	// srpSessionTTL - время между первым и вторым шагом входа по SRP.
+	srpSessionTTL = 2 * time.Minute
+	// maxSaltSize - предел размера соли, присланной клиентом.
+	maxSaltSize = 64
+)
+
+var ErrInvalidSRPVerifier = errors.New(errText.InvalidSRPVerifierError)
+
+// RegisterSRP - регистрация пользователя с солью и верификатором,
+// вычисленными клиентом. Возвращает также коды восстановления.
+func (kp *KeepService) RegisterSRP(ctx context.Context, login string, salt, verifier []byte) (int64, []string, error) {
+	kp.log.Debug().Msg("called 'service.RegisterSRP'")
+	if login == "" {
+		return -1, nil, ErrEmptyLogin
+	}
+	if err := checkVerifier(salt, verifier); err != nil {
+		return -1, nil, err
+	}
+	return kp.saveUser(ctx, models.UserModel{
+		Login:       login,
+		SRPSalt:     salt,
+		SRPVerifier: verifier,
+	})
+}
+
+// StartSRP - первый шаг входа: по открытому числу клиента A возвращает
+// id сессии, соль пользователя и открытое число сервера B. Для
+// несуществующего логина и учетной записи без верификатора сохраняется
+// сессия без пользователя с солью и верификатором, производными от логина:
+// ответ неотличим от настоящего, а второй шаг завершается той же ошибкой,
+// что и при неверном пароле.
+func (kp *KeepService) StartSRP(ctx context.Context, login string, clientA []byte) (string, []byte, []byte, error) {
+	kp.log.Debug().Msg("called 'service.StartSRP'")
+	user, err := kp.stor.GetUser(ctx, login)
+	if err != nil && !errors.Is(err, storage.ErrUserNotExist) {
+		kp.log.Error().Err(err).Msg("Get user error")
+		return "", nil, nil, err
+	}
+	uID, salt, verifier := int(user.UserID), user.SRPSalt, user.SRPVerifier
+	if err != nil || len(verifier) == 0 {
+		uID = 0
+		salt, verifier = kp.decoys.get(login)
+	}
+	h, err := srp.Start(verifier, clientA)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	id, err := kp.stor.CreateSRPSession(ctx, uID, h, srpSessionTTL)
+	if err != nil {
+		kp.log.Error().Err(err).Msg("Create srp session error")
+		return "", nil, nil, err
+	}
+	return id, salt, h.B, nil
+}
+
+// VerifySRP - второй шаг входа: проверка доказательства клиента M1.
+// Возвращает пользователя и доказательство сервера M2.
+func (kp *KeepService) VerifySRP(ctx context.Context, sessionID string, clientM1 []byte) (models.UserModel, []byte, error) {
+	kp.log.Debug().Msg("called 'service.VerifySRP'")
+	user, m2, err := kp.checkSRPProof(ctx, sessionID, clientM1)
+	if err != nil {
+		return models.UserModel{}, nil, err
+	}
+	if user.Locked {
+		return models.UserModel{}, nil, ErrAccountLocked
+	}
+	kp.touchLogin(ctx, int(user.UserID))
+	return user, m2, nil
+}
+
+// checkSRPProof - проверка доказательства клиента M1 для сессии sessionID;
+// сессия удаляется. Сессия без пользователя проверяется по случайному
+// верификатору и всегда завершается ErrProofMismatch.
+func (kp *KeepService) checkSRPProof(ctx context.Context, sessionID string, clientM1 []byte) (models.UserModel, []byte, error) {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return models.UserModel{}, nil, storage.ErrSRPSessionNotExist
+	}
+	uID, h, err := kp.stor.TakeSRPSession(ctx, sessionID)
+	if err != nil {
+		return models.UserModel{}, nil, err
+	}
+	var user models.UserModel
+	verifier := kp.decoys.verifier
+	if uID != 0 {
+		user, err = kp.stor.GetUserByID(ctx, uID)
+		if errors.Is(err, storage.ErrUserNotExist) {
+			// Пользователь удален после первого шага.
+			return models.UserModel{}, nil, srp.ErrProofMismatch
+		}
+		if err != nil {
+			return models.UserModel{}, nil, err
+		}
+		verifier = user.SRPVerifier
+	}
+	m2, err := h.Verify(verifier, clientM1)
+	if err == nil && uID == 0 {
+		err = srp.ErrProofMismatch
+	}
+	if err != nil {
+		kp.log.Debug().Err(err).Int("User ID", uID).Msg("SRP verification failed")
+		return models.UserModel{}, nil, err
+	}
+	return user, m2, nil
+}
+
+// newCredentials - соль и верификатор нового пароля: присланные клиентом SRP
+// или вычисленные из пароля клиента без поддержки SRP.
+func newCredentials(pass string, salt, verifier []byte) ([]byte, []byte, error) {
+	if len(verifier) > 0 {
+		return salt, verifier, checkVerifier(salt, verifier)
+	}
+	if pass == "" {
+		return nil, nil, ErrEmptyPassword
+	}
+	return newVerifier(pass)
+}
+
+// newVerifier - соль и верификатор SRP для пароля, присланного клиентом
+// без поддержки SRP.
+func newVerifier(pass string) ([]byte, []byte, error) {
+	salt, err := srp.NewSalt()
+	if err != nil {
+		return nil, nil, err
+	}
+	return salt, srp.Verifier(salt, pass), nil
+}
+
+func checkVerifier(salt, verifier []byte) error {
+	if len(salt) < srp.SaltSize || len(salt) > maxSaltSize || !srp.ValidVerifier(verifier) {
+		return ErrInvalidSRPVerifier
+	}
+	return nil
+}
+
+// checkPassword - проверка пароля по верификатору SRP или bcrypt-хешу.
+func checkPassword(user models.UserModel, pass string) bool {
+	if len(user.SRPVerifier) > 0 {
+		return srp.CheckPassword(user.SRPSalt, user.SRPVerifier, pass)
+	}
+	return matchPass(pass, user.Hash)
+}
+
+// decoys - соль и верификатор несуществующих пользователей, чтобы вход
+// не раскрывал, зарегистрирован ли логин. Ключ задается в конфигурации:
+// соль логина не меняется между перезапусками и совпадает на всех репликах.
+type decoys struct {
+	key []byte
+	// verifier - верификатор для проверки доказательства в сессии без
+	// пользователя, чтобы она занимала столько же времени, сколько настоящая.
+	verifier []byte
+}
+
+func newDecoys(key []byte) decoys {
+	d := decoys{key: key}
+	_, d.verifier = d.get("")
+	return d
+}
+
+func (d decoys) get(login string) ([]byte, []byte) {
+	mac := hmac.New(sha256.New, d.key)
+	mac.Write([]byte(login))
+	sum := mac.Sum(nil)
+	return sum[:srp.SaltSize], srp.Verifier(sum, login)
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+	"github.com/Dorrrke/GophKeeper-server/internal/storage"
+	gophkeeperv1 "github.com/Dorrrke/goph-keeper-proto/gen/go/gophkeeper"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// maxIdempotencyKeyLen - предел длины ключа идемпотентности.
+	maxIdempotencyKeyLen = 128
+	// syncLease - время, после которого незавершенная синхронизация с ключом
+	// считается брошенной и может быть выполнена повтором запроса.
+	syncLease = 5 * time.Minute
+	// syncCursorTTL - время, в течение которого доступны остальные страницы
+	// ответа синхронизации без ключа идемпотентности.
+	syncCursorTTL = time.Hour
+)
+
+var (
+	ErrInvalidIdempotencyKey = errors.New(errText.InvalidIdempotencyKeyError)
+	ErrSyncPageItems         = errors.New(errText.SyncPageItemsError)
+)
+
+// syncPageToken - позиция следующей страницы ответа синхронизации.
+type syncPageToken struct {
+	Cursor string `json:"c"`
+	Pos    int    `json:"p"`
+}
+
+// syncPage - следующая страница ответа синхронизации по токену. Повтор
+// запроса с тем же токеном возвращает ту же страницу.
+func (kp *KeepService) syncPage(req *gophkeeperv1.SyncDBRequest, uID int) (*gophkeeperv1.SyncDBResponse, error) {
+	if items.Count(req) > 0 {
+		return nil, ErrSyncPageItems
+	}
+	token, err := decodeSyncPageToken(req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+	page, next, err := kp.stor.SyncPage(context.Background(), uID, token.Cursor, token.Pos, kp.syncPaging(req))
+	if err != nil {
+		return nil, err
+	}
+	resp := syncResponse(page, "")
+	if next >= 0 {
+		resp.NextPageToken = encodeSyncPageToken(syncPageToken{Cursor: token.Cursor, Pos: next})
+	}
+	return resp, nil
+}
+
+// syncPaging - ограничения страницы: размер, запрошенный клиентом, не может
+// превышать настроенный.
+func (kp *KeepService) syncPaging(req *gophkeeperv1.SyncDBRequest) storage.SyncPaging {
+	size := int(req.GetPageSize())
+	if size <= 0 || size > kp.limits.SyncPageSize {
+		size = kp.limits.SyncPageSize
+	}
+	return storage.SyncPaging{Size: size, Bytes: kp.limits.SyncPageBytes, TTL: syncCursorTTL}
+}
+
+// syncResponse - страница ответа; cursor - курсор остальных страниц,
+// пустой, если страница единственная.
+func syncResponse(page items.Set, cursor string) *gophkeeperv1.SyncDBResponse {
+	resp := &gophkeeperv1.SyncDBResponse{}
+	page.ToProto(resp)
+	if cursor != "" {
+		resp.NextPageToken = encodeSyncPageToken(syncPageToken{Cursor: cursor})
+	}
+	return resp
+}
+
+func encodeSyncPageToken(t syncPageToken) string {
+	data, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSyncPageToken(s string) (syncPageToken, error) {
+	var t syncPageToken
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return t, ErrInvalidPageToken
+	}
+	if err := json.Unmarshal(data, &t); err != nil || t.Pos < 0 {
+		return t, ErrInvalidPageToken
+	}
+	if _, err := uuid.Parse(t.Cursor); err != nil {
+		return t, ErrInvalidPageToken
+	}
+	return t, nil
+}
+
+// idempotentSync - синхронизация с ключом идемпотентности. Ключ относится
+// к пользователю; повтор с тем же ключом, но другим содержимым запроса,
+// отклоняется.
+func (kp *KeepService) idempotentSync(req *gophkeeperv1.SyncDBRequest, set items.Set, uID int, device, key string) (*gophkeeperv1.SyncDBResponse, error) {
+	if !validIdempotencyKey(key) {
+		return nil, ErrInvalidIdempotencyKey
+	}
+	hash, err := requestHash(req)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	stored, err := kp.stor.BeginSyncRequest(ctx, uID, key, hash, kp.limits.IdempotencyTTL, syncLease)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil {
+		kp.log.Debug().Int("User ID", uID).Msg("Replay sync response")
+		resp := &gophkeeperv1.SyncDBResponse{}
+		if err := proto.Unmarshal(stored, resp); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+
+	// Ответ сохраняется в транзакции синхронизации, поэтому повтор получает
+	// его, только если записи зафиксированы. Повтор возвращает токен
+	// следующей страницы, поэтому курсор хранится не меньше ключа.
+	paging := kp.syncPaging(req)
+	paging.TTL = max(paging.TTL, kp.limits.IdempotencyTTL)
+	resp, err := kp.syncDB(set, uID, device, paging, &storage.SyncRequest{
+		Key: key,
+		Encode: func(page items.Set, cursor string) ([]byte, error) {
+			return proto.Marshal(syncResponse(page, cursor))
+		},
+	})
+	if err != nil {
+		if aErr := kp.stor.AbortSyncRequest(ctx, uID, key); aErr != nil {
+			kp.log.Error().Err(aErr).Int("User ID", uID).Msg("Abort sync request error")
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// requestHash - SHA-256 детерминированной сериализации запроса.
+func requestHash(req *gophkeeperv1.SyncDBRequest) ([]byte, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	return sum[:], nil
+}
+
+// validIdempotencyKey - непустой ключ из печатных символов ASCII.
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLen {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return key != ""
+}
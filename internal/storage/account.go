@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/srp"
+	"github.com/Dorrrke/GophKeeper-server/internal/storage/queries"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// GetUserByID - пользователь по id.
+func (s *KeepStorage) GetUserByID(ctx context.Context, uID int) (models.UserModel, error) {
+	user, err := queries.New(s.db).GetUserByID(ctx, uID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.UserModel{}, ErrUserNotExist
+		}
+		return models.UserModel{}, err
+	}
+	return user, nil
+}
+
+// GetTokenVersion - версия действующих токенов пользователя и признак
+// блокировки учетной записи.
+func (s *KeepStorage) GetTokenVersion(ctx context.Context, uID int) (int, bool, error) {
+	version, locked, err := queries.New(s.db).GetTokenVersion(ctx, uID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, ErrUserNotExist
+	}
+	return version, locked, err
+}
+
+// ChangePassword - новые соль и верификатор SRP с отзывом выданных токенов.
+// Обертки ключа bundles сохраняются в той же транзакции. Возвращает новую
+// версию токенов.
+func (s *KeepStorage) ChangePassword(ctx context.Context, uID int, salt, verifier []byte,
+	bundles []models.KeyBundle) (int, error) {
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	version, err := q.UpdatePassword(ctx, uID, salt, verifier)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrUserNotExist
+		}
+		return 0, err
+	}
+	for _, b := range bundles {
+		if _, err := putKeyBundle(ctx, q, uID, b); err != nil {
+			return 0, err
+		}
+	}
+	return version, tx.Commit(ctx)
+}
+
+// ChangeLogin - смена логина пользователя.
+func (s *KeepStorage) ChangeLogin(ctx context.Context, uID int, login string) error {
+	err := queries.New(s.db).UpdateLogin(ctx, uID, login)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return ErrUserAlredyExist
+	}
+	return err
+}
+
+// DeleteUser - удаление пользователя и всех его записей в одной транзакции.
+func (s *KeepStorage) DeleteUser(ctx context.Context, uID int) error {
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := q.DeleteUserItems(ctx, uID); err != nil {
+		s.zlog.Error().Err(err).Msg("Delete user items error")
+		return err
+	}
+	if err := q.DeleteUser(ctx, uID); err != nil {
+		s.zlog.Error().Err(err).Msg("Delete user error")
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// UpgradeToSRP - перевод учетной записи с bcrypt-хешем на SRP.
+func (s *KeepStorage) UpgradeToSRP(ctx context.Context, uID int, salt, verifier []byte) error {
+	return queries.New(s.db).UpgradeToSRP(ctx, uID, salt, verifier)
+}
+
+// CreateSRPSession - сохранение первого шага входа по SRP; uID 0 - сессия
+// без пользователя. Заодно удаляются истекшие сессии.
+func (s *KeepStorage) CreateSRPSession(ctx context.Context, uID int, h srp.Handshake, ttl time.Duration) (string, error) {
+	q := queries.New(s.db)
+	if err := q.DeleteExpiredSRPSessions(ctx); err != nil {
+		s.zlog.Error().Err(err).Msg("Delete expired srp sessions error")
+	}
+	return q.CreateSRPSession(ctx, uID, h, time.Now().Add(ttl))
+}
+
+// TakeSRPSession - незавершенный вход по SRP; сессия используется однократно.
+// uID 0 - сессия без пользователя.
+func (s *KeepStorage) TakeSRPSession(ctx context.Context, id string) (int, srp.Handshake, error) {
+	uID, h, err := queries.New(s.db).TakeSRPSession(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, srp.Handshake{}, ErrSRPSessionNotExist
+	}
+	return uID, h, err
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/recovery"
+	"github.com/Dorrrke/GophKeeper-server/internal/storage/queries"
+	"github.com/jackc/pgx/v5"
+)
+
+// beginMaintenanceTx - начало служебной транзакции, в которой политики RLS
+// пропускают строки всех пользователей.
+func (s *KeepStorage) beginMaintenanceTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, *queries.Queries, error) {
+	tx, err := s.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	q := queries.New(tx)
+	if err := q.SetMaintenance(ctx); err != nil {
+		tx.Rollback(ctx)
+		return nil, nil, err
+	}
+	return tx, q, nil
+}
+
+// UserSummaries - сведения о пользователях с числом записей и временем
+// последней активности; login ограничивает выборку одним пользователем.
+func (s *KeepStorage) UserSummaries(ctx context.Context, login string) ([]models.UserSummary, error) {
+	tx, q, err := s.beginMaintenanceTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	users, err := q.ListUserSummaries(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	if login != "" && len(users) == 0 {
+		return nil, ErrUserNotExist
+	}
+	var uID int
+	if login != "" {
+		uID = int(users[0].UserID)
+	}
+	stats, err := q.ListItemStats(ctx, uID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]*models.UserSummary, len(users))
+	for i := range users {
+		users[i].Items = make(map[string]int)
+		users[i].LastActivity = users[i].LastLogin
+		byID[users[i].UserID] = &users[i]
+	}
+	for _, st := range stats {
+		u, ok := byID[st.UserID]
+		if !ok {
+			continue
+		}
+		if st.Type != "" {
+			u.Items[st.Type] = st.Count
+		}
+		if st.LastUpdate != nil && (u.LastActivity == nil || st.LastUpdate.After(*u.LastActivity)) {
+			u.LastActivity = st.LastUpdate
+		}
+	}
+	return users, tx.Commit(ctx)
+}
+
+// LockUser - блокировка учетной записи: вход запрещается, выданные токены
+// отзываются. Возвращает false, если учетная запись уже заблокирована.
+func (s *KeepStorage) LockUser(ctx context.Context, uID int) (bool, error) {
+	return s.adminUpdate(ctx, uID, recovery.EventAccountLocked, func(q *queries.Queries) (bool, error) {
+		return q.LockUser(ctx, uID)
+	})
+}
+
+// UnlockUser - снятие блокировки. Возвращает false, если учетная запись не
+// была заблокирована.
+func (s *KeepStorage) UnlockUser(ctx context.Context, uID int) (bool, error) {
+	return s.adminUpdate(ctx, uID, recovery.EventAccountUnlocked, func(q *queries.Queries) (bool, error) {
+		return q.UnlockUser(ctx, uID)
+	})
+}
+
+// RevokeTokens - отзыв всех выданных токенов пользователя.
+func (s *KeepStorage) RevokeTokens(ctx context.Context, uID int) error {
+	_, err := s.adminUpdate(ctx, uID, recovery.EventTokensRevoked, func(q *queries.Queries) (bool, error) {
+		_, err := q.RevokeTokens(ctx, uID)
+		return err == nil, err
+	})
+	return err
+}
+
+// adminUpdate - изменение учетной записи администратором с записью события
+// в журнал пользователя, если изменение выполнено.
+func (s *KeepStorage) adminUpdate(ctx context.Context, uID int, event string,
+	update func(q *queries.Queries) (bool, error)) (bool, error) {
+	tx, q, err := s.beginMaintenanceTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := q.GetUserByID(ctx, uID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrUserNotExist
+		}
+		return false, err
+	}
+	changed, err := update(q)
+	if err != nil || !changed {
+		return false, err
+	}
+	if err := q.InsertAccountEvent(ctx, uID, 0, event, map[string]string{"by": "admin"}); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+// TouchLogin - время последнего входа пользователя.
+func (s *KeepStorage) TouchLogin(ctx context.Context, uID int) error {
+	return queries.New(s.db).UpdateLastLogin(ctx, uID)
+}
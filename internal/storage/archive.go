@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/archive"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/events"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+	"github.com/Dorrrke/GophKeeper-server/internal/storage/queries"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ExportItems - все неудаленные записи пользователя на один момент времени.
+func (s *KeepStorage) ExportItems(ctx context.Context, uID int) (items.Set, error) {
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	set, err := exportItems(ctx, q, uID)
+	if err != nil {
+		s.zlog.Error().Err(err).Msg("Export items error")
+		return nil, err
+	}
+	return set, tx.Commit(ctx)
+}
+
+// exportItems - все неудаленные записи пользователя в транзакции q.
+func exportItems(ctx context.Context, q *queries.Queries, uID int) (items.Set, error) {
+	set := make(items.Set)
+	for _, t := range items.Types() {
+		list, err := q.ListItemsExcept(ctx, t, uID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.Name, err)
+		}
+		for _, it := range list {
+			if !it.Deleted {
+				set[t.Name] = append(set[t.Name], it)
+			}
+		}
+	}
+	return set, nil
+}
+
+// ImportItems - слияние записей архива с хранилищем пользователя в одной
+// транзакции. Записи получают новые id; совпадение имени с существующей
+// записью того же типа обрабатывается по policy. Папки с совпадающими
+// именами объединяются при любой policy. Записи типов Opaque сохраняют id
+// и сопоставляются по нему; KeepBoth для них равносилен Skip.
+func (s *KeepStorage) ImportItems(ctx context.Context, uID int, set items.Set,
+	policy archive.DuplicatePolicy) (archive.ImportResult, error) {
+	var res archive.ImportResult
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{})
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback(ctx)
+
+	types := sortedTypes()
+
+	// Клиенты получают время изменения с точностью до секунды (RFC 3339)
+	// и сравнивают его со своим, поэтому дробная часть отбрасывается.
+	now := time.Now().UTC().Truncate(time.Second)
+	folderIDs := make(map[string]string)
+	var changes []events.Change
+	for _, t := range types {
+		if len(set[t.Name]) == 0 {
+			continue
+		}
+		current, err := q.ListItemsExcept(ctx, t, uID, nil)
+		if err != nil {
+			s.zlog.Error().Err(err).Str("type", t.Name).Msg("Select items for import error")
+			return res, err
+		}
+		byName := make(map[string]items.Item, len(current))
+		byID := make(map[string]bool, len(current))
+		for _, it := range current {
+			if !it.Deleted {
+				byName[it.Name] = it
+				byID[it.ID] = true
+			}
+		}
+
+		for _, it := range set[t.Name] {
+			if t.Opaque {
+				exists := byID[it.ID]
+				if exists && policy != archive.Overwrite {
+					res.Skipped++
+					continue
+				}
+				it.UserID, it.Deleted, it.Updated = uID, false, now
+				ok, err := upsertOwnItem(ctx, tx, t, it)
+				if err != nil {
+					s.zlog.Error().Err(err).Str("type", t.Name).Msg("Import item error")
+					return archive.ImportResult{}, err
+				}
+				switch {
+				case !ok:
+					// id занят записью другого пользователя.
+					res.Skipped++
+					continue
+				case exists:
+					res.Updated++
+				default:
+					res.Created++
+				}
+				changes = append(changes, events.Change{Type: t.Name, ID: it.ID})
+				continue
+			}
+			oldID := it.ID
+			it.ID = uuid.NewString()
+			it.UserID = uID
+			it.Deleted = false
+			it.Updated = now
+			if t.Meta {
+				it.FolderID = folderIDs[it.FolderID]
+			}
+
+			if existing, ok := byName[it.Name]; ok {
+				switch {
+				case t == items.Folder || policy == archive.Skip:
+					folderIDs[oldID] = existing.ID
+					res.Skipped++
+					continue
+				case policy == archive.Overwrite:
+					it.ID = existing.ID
+					res.Updated++
+				case policy == archive.KeepBoth:
+					it.Name = importedName(it.Name, byName)
+					byName[it.Name] = it
+					res.Created++
+				}
+			} else {
+				res.Created++
+			}
+			folderIDs[oldID] = it.ID
+			ok, err := q.UpsertItem(ctx, t, it)
+			if err != nil {
+				s.zlog.Error().Err(err).Str("type", t.Name).Msg("Import item error")
+				return archive.ImportResult{}, err
+			}
+			if ok {
+				changes = append(changes, events.Change{Type: t.Name, ID: it.ID})
+			}
+		}
+	}
+	// Импорт уведомляет все устройства пользователя.
+	if err := publishChanges(ctx, q, uID, "", changes); err != nil {
+		return archive.ImportResult{}, err
+	}
+	return res, tx.Commit(ctx)
+}
+
+// upsertOwnItem - UpsertItem в точке сохранения: если id занят записью
+// другого пользователя, политика доступа прерывает только эту вставку,
+// и функция возвращает false.
+func upsertOwnItem(ctx context.Context, tx pgx.Tx, t *items.Type, it items.Item) (bool, error) {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer sp.Rollback(ctx)
+	ok, err := queries.New(sp).UpsertItem(ctx, t, it)
+	if isRLSViolation(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return ok, sp.Commit(ctx)
+}
+
+// importedName - имя для копии записи, не совпадающее с существующими.
+func importedName(name string, taken map[string]items.Item) string {
+	for i := 1; ; i++ {
+		suffix := " (imported)"
+		if i > 1 {
+			suffix = fmt.Sprintf(" (imported %d)", i)
+		}
+		base := []rune(name)
+		if len(base)+len([]rune(suffix)) > items.MaxNameLen {
+			base = base[:items.MaxNameLen-len([]rune(suffix))]
+		}
+		candidate := string(base) + suffix
+		if _, ok := taken[candidate]; !ok {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/backup"
+	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+	"github.com/Dorrrke/GophKeeper-server/internal/storage/queries"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrBackupSchemaMismatch  = errors.New(errText.BackupSchemaMismatchError)
+	ErrRestoreTargetNotEmpty = errors.New(errText.RestoreTargetNotEmptyError)
+	ErrUserNotInBackup       = errors.New(errText.UserNotInBackupError)
+
+	errBackupUserRef = fmt.Errorf("%w: bad user reference", backup.ErrInvalidBackup)
+)
+
+// backupTable - таблица резервной копии.
+type backupTable struct {
+	name string
+	// owners - колонки со ссылкой на пользователя: при восстановлении одного
+	// пользователя берутся строки, где он указан хотя бы в одной из них.
+	owners []string
+	// refs - необязательные ссылки на пользователя; обнуляются, если
+	// пользователя нет в базе.
+	refs []string
+}
+
+// backupTables - таблицы в порядке восстановления: пользователи, записи по
+// возрастанию Order, затем остальные данные пользователей. Сессии SRP
+// и служебные таблицы синхронизации в копию не входят.
+func backupTables() []backupTable {
+	tables := []backupTable{{name: "users", owners: []string{"uid"}}}
+	for _, t := range sortedTypes() {
+		tables = append(tables, backupTable{name: t.Table, owners: []string{"uid"}})
+	}
+	return append(tables,
+		backupTable{name: "key_bundles", owners: []string{"uid"}},
+		backupTable{name: "recovery_codes", owners: []string{"uid"}},
+		backupTable{name: "emergency_contacts", owners: []string{"owner_id", "contact_id"}},
+		backupTable{name: "account_events", owners: []string{"uid"}, refs: []string{"actor_id"}},
+	)
+}
+
+// RestoreResult - число восстановленных строк по таблицам.
+type RestoreResult struct {
+	Users int            `json:"users"`
+	Rows  map[string]int `json:"rows"`
+	// Skipped - строки, ссылающиеся на пользователей, которых нет в базе.
+	Skipped int `json:"skipped"`
+}
+
+// Backup - согласованная копия пользователей и всех таблиц хранилища.
+// Данные читаются в одной транзакции REPEATABLE READ; key, если задан,
+// шифрует копию.
+func (s *KeepStorage) Backup(ctx context.Context, out io.Writer, key []byte) (backup.Meta, error) {
+	tx, q, err := s.beginMaintenanceTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return backup.Meta{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	version, dirty, err := q.SchemaVersion(ctx)
+	if err != nil {
+		return backup.Meta{}, err
+	}
+	if dirty {
+		return backup.Meta{}, fmt.Errorf("database schema is dirty at version %d", version)
+	}
+	meta := backup.Meta{Created: time.Now().UTC(), SchemaVersion: version}
+	w, err := backup.NewWriter(out, key, meta)
+	if err != nil {
+		return backup.Meta{}, err
+	}
+	for _, t := range backupTables() {
+		err := q.DumpTable(ctx, t.name, func(row []byte) error {
+			return w.Write(backup.Record{Table: t.name, Row: row})
+		})
+		if err != nil {
+			return backup.Meta{}, fmt.Errorf("%s: %w", t.name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return backup.Meta{}, err
+	}
+	meta.Version = backup.Version
+	return meta, tx.Commit(ctx)
+}
+
+// Restore - восстановление из копии в одной транзакции. При пустом login
+// восстанавливается вся копия, и в базе не должно быть пользователей.
+// Иначе восстанавливается один пользователь: его текущие данные удаляются,
+// id сохраняется, если логин уже есть в базе; ссылки на других пользователей
+// сопоставляются по логину. Изменения применяются, только если копия
+// прочитана целиком и контрольная сумма совпала.
+func (s *KeepStorage) Restore(ctx context.Context, in io.Reader, key []byte, login string) (RestoreResult, error) {
+	r, err := backup.NewReader(in, key)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	tx, q, err := s.beginMaintenanceTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	version, _, err := q.SchemaVersion(ctx)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	if version != r.Meta().SchemaVersion {
+		return RestoreResult{}, fmt.Errorf("%w: backup %d, database %d",
+			ErrBackupSchemaMismatch, r.Meta().SchemaVersion, version)
+	}
+	if login == "" {
+		n, err := q.CountUsers(ctx)
+		if err != nil {
+			return RestoreResult{}, err
+		}
+		if n > 0 {
+			return RestoreResult{}, ErrRestoreTargetNotEmpty
+		}
+	}
+
+	rs := &restorer{
+		q:       q,
+		login:   login,
+		logins:  make(map[string]string),
+		ids:     make(map[string]*int64),
+		serials: make(map[string][]string),
+		res:     RestoreResult{Rows: make(map[string]int)},
+	}
+	tables := make(map[string]backupTable)
+	for _, t := range backupTables() {
+		tables[t.name] = t
+	}
+	for {
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return RestoreResult{}, err
+		}
+		t, ok := tables[rec.Table]
+		if !ok {
+			return RestoreResult{}, fmt.Errorf("%w: unknown table %q", backup.ErrInvalidBackup, rec.Table)
+		}
+		if err := rs.restore(ctx, t, rec.Row); err != nil {
+			return RestoreResult{}, fmt.Errorf("%s: %w", t.name, err)
+		}
+	}
+	if login != "" && rs.userID == "" {
+		return RestoreResult{}, ErrUserNotInBackup
+	}
+	if login == "" {
+		for _, t := range backupTables() {
+			if err := q.ResetSequences(ctx, t.name); err != nil {
+				return RestoreResult{}, err
+			}
+		}
+	}
+	return rs.res, tx.Commit(ctx)
+}
+
+// restorer - состояние восстановления.
+type restorer struct {
+	q     *queries.Queries
+	login string
+	// userID - id восстанавливаемого пользователя в копии.
+	userID string
+	// logins - логины пользователей копии по id в копии.
+	logins map[string]string
+	// ids - id пользователей копии в базе; nil, если пользователя в базе нет.
+	ids map[string]*int64
+	// serials - serial-колонки по таблицам.
+	serials    map[string][]string
+	tablesDone bool
+	res        RestoreResult
+}
+
+func (rs *restorer) restore(ctx context.Context, t backupTable, data json.RawMessage) error {
+	var row map[string]json.RawMessage
+	if err := json.Unmarshal(data, &row); err != nil {
+		return fmt.Errorf("%w: %v", backup.ErrInvalidBackup, err)
+	}
+	if t.name == "users" {
+		return rs.restoreUser(ctx, row)
+	}
+	rs.tablesDone = true
+	if rs.login == "" {
+		_, err := rs.q.InsertJSONRow(ctx, t.name, row, "")
+		if err == nil {
+			rs.res.Rows[t.name]++
+		}
+		return err
+	}
+
+	owned := false
+	for _, col := range t.owners {
+		id, err := userRef(row, col)
+		if err != nil {
+			return err
+		}
+		owned = owned || id == rs.userID
+	}
+	if !owned {
+		return nil
+	}
+	for _, col := range t.owners {
+		id, _ := userRef(row, col)
+		target, err := rs.targetID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if target == nil {
+			rs.res.Skipped++
+			return nil
+		}
+		row[col] = json.RawMessage(strconv.FormatInt(*target, 10))
+	}
+	for _, col := range t.refs {
+		id, err := userRef(row, col)
+		if err != nil {
+			return err
+		}
+		if id == "" {
+			continue
+		}
+		target, err := rs.targetID(ctx, id)
+		if err != nil {
+			return err
+		}
+		row[col] = json.RawMessage("null")
+		if target != nil {
+			row[col] = json.RawMessage(strconv.FormatInt(*target, 10))
+		}
+	}
+	// Значения serial-колонок назначаются заново, чтобы не пересечься
+	// со строками других пользователей.
+	if err := rs.dropSerials(ctx, t.name, row); err != nil {
+		return err
+	}
+	if _, err := rs.q.InsertJSONRow(ctx, t.name, row, ""); err != nil {
+		return err
+	}
+	rs.res.Rows[t.name]++
+	return nil
+}
+
+func (rs *restorer) restoreUser(ctx context.Context, row map[string]json.RawMessage) error {
+	if rs.tablesDone {
+		return fmt.Errorf("%w: users must come first", backup.ErrInvalidBackup)
+	}
+	id, err := userRef(row, "uid")
+	if err != nil || id == "" {
+		return errBackupUserRef
+	}
+	var login string
+	if err := json.Unmarshal(row["login"], &login); err != nil {
+		return fmt.Errorf("%w: %v", backup.ErrInvalidBackup, err)
+	}
+	if _, ok := rs.logins[id]; ok {
+		return fmt.Errorf("%w: duplicate user %s", backup.ErrInvalidBackup, id)
+	}
+	rs.logins[id] = login
+
+	if rs.login == "" {
+		if _, err := rs.q.InsertJSONRow(ctx, "users", row, ""); err != nil {
+			return err
+		}
+		rs.res.Users++
+		return nil
+	}
+	if login != rs.login {
+		return nil
+	}
+	rs.userID = id
+	current, err := rs.q.GetUserByLogin(ctx, login)
+	switch {
+	case err == nil:
+		// Пользователь пересоздается с прежним id; его данные удаляются
+		// каскадно.
+		if err := rs.q.DeleteUserItems(ctx, int(current.UserID)); err != nil {
+			return err
+		}
+		if err := rs.q.DeleteUser(ctx, int(current.UserID)); err != nil {
+			return err
+		}
+		row["uid"] = json.RawMessage(strconv.FormatInt(current.UserID, 10))
+	case errors.Is(err, pgx.ErrNoRows):
+		delete(row, "uid")
+	default:
+		return err
+	}
+	uID, err := rs.q.InsertJSONRow(ctx, "users", row, "uid")
+	if err != nil {
+		return err
+	}
+	rs.ids[id] = &uID
+	rs.res.Users++
+	return nil
+}
+
+// targetID - id пользователя копии в базе, найденный по логину.
+func (rs *restorer) targetID(ctx context.Context, id string) (*int64, error) {
+	if target, ok := rs.ids[id]; ok {
+		return target, nil
+	}
+	login, ok := rs.logins[id]
+	if !ok {
+		return nil, errBackupUserRef
+	}
+	user, err := rs.q.GetUserByLogin(ctx, login)
+	switch {
+	case err == nil:
+		rs.ids[id] = &user.UserID
+	case errors.Is(err, pgx.ErrNoRows):
+		rs.ids[id] = nil
+	default:
+		return nil, err
+	}
+	return rs.ids[id], nil
+}
+
+func (rs *restorer) dropSerials(ctx context.Context, table string, row map[string]json.RawMessage) error {
+	cols, ok := rs.serials[table]
+	if !ok {
+		var err error
+		if cols, err = rs.q.SerialColumns(ctx, table); err != nil {
+			return err
+		}
+		rs.serials[table] = cols
+	}
+	for _, col := range cols {
+		delete(row, col)
+	}
+	return nil
+}
+
+// userRef - id пользователя из колонки строки в виде строки; пустой для null.
+func userRef(row map[string]json.RawMessage, col string) (string, error) {
+	raw, ok := row[col]
+	if !ok {
+		return "", fmt.Errorf("%w: missing column %s", backup.ErrInvalidBackup, col)
+	}
+	if string(raw) == "null" {
+		return "", nil
+	}
+	var id int64
+	if err := json.Unmarshal(raw, &id); err != nil {
+		return "", errBackupUserRef
+	}
+	return strconv.FormatInt(id, 10), nil
+}
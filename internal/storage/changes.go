@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/events"
+	"github.com/Dorrrke/GophKeeper-server/internal/storage/queries"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// changesChannel - канал NOTIFY, через который экземпляры сервера
+	// узнают об изменениях, зафиксированных другими экземплярами.
+	changesChannel = "gophkeeper_changes"
+	// maxNotifyPayload - предел размера уведомления (в PostgreSQL - 8000 байт).
+	maxNotifyPayload = 7900
+	// subscriberBuffer - число пакетов, ожидающих отправки подписчику.
+	subscriberBuffer = 16
+
+	listenRetryMin = time.Second
+	listenRetryMax = time.Minute
+)
+
+// Subscription - подписка устройства на изменения хранилища пользователя.
+type Subscription struct {
+	// C - пакеты изменений, сделанных другими устройствами.
+	C <-chan events.Batch
+
+	ch     chan events.Batch
+	uID    int
+	device string
+	hub    *hub
+}
+
+// Close - отмена подписки.
+func (sub *Subscription) Close() {
+	sub.hub.remove(sub)
+}
+
+// hub - подписки экземпляра сервера по пользователям.
+type hub struct {
+	mu   sync.Mutex
+	subs map[int]map[*Subscription]struct{}
+}
+
+func newHub() *hub {
+	return &hub{subs: make(map[int]map[*Subscription]struct{})}
+}
+
+func (h *hub) add(uID int, device string) *Subscription {
+	ch := make(chan events.Batch, subscriberBuffer)
+	sub := &Subscription{C: ch, ch: ch, uID: uID, device: device, hub: h}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[uID] == nil {
+		h.subs[uID] = make(map[*Subscription]struct{})
+	}
+	h.subs[uID][sub] = struct{}{}
+	return sub
+}
+
+func (h *hub) remove(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subs[sub.uID], sub)
+	if len(h.subs[sub.uID]) == 0 {
+		delete(h.subs, sub.uID)
+	}
+}
+
+// dispatch - отправка пакета подписчикам пользователя, кроме устройства-источника.
+// Если подписчик не успевает читать, его очередь заменяется одним пакетом
+// с Resync.
+func (h *hub) dispatch(b events.Batch) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs[b.UserID] {
+		if b.Device != "" && sub.device == b.Device {
+			continue
+		}
+		select {
+		case sub.ch <- b:
+			continue
+		default:
+		}
+	drain:
+		for {
+			select {
+			case <-sub.ch:
+			default:
+				break drain
+			}
+		}
+		sub.ch <- events.Batch{UserID: b.UserID, Revision: b.Revision, Resync: true}
+	}
+}
+
+// resyncAll - пакет Resync всем подписчикам, например после потери
+// соединения, на котором могли пропасть уведомления.
+func (h *hub) resyncAll() {
+	h.mu.Lock()
+	users := make([]int, 0, len(h.subs))
+	for uID := range h.subs {
+		users = append(users, uID)
+	}
+	h.mu.Unlock()
+	for _, uID := range users {
+		h.dispatch(events.Batch{UserID: uID, Resync: true})
+	}
+}
+
+// Subscribe - подписка устройства device на изменения хранилища пользователя.
+func (s *KeepStorage) Subscribe(uID int, device string) *Subscription {
+	return s.changes.add(uID, device)
+}
+
+// ListenChanges - получение уведомлений об изменениях от всех экземпляров
+// сервера и рассылка их подписчикам. Работает до отмены ctx, переподключаясь
+// к базе при ошибках.
+func (s *KeepStorage) ListenChanges(ctx context.Context) {
+	retry := listenRetryMin
+	for {
+		err := s.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		s.zlog.Error().Err(err).Dur("retry", retry).Msg("Listen changes error")
+		s.changes.resyncAll()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		retry = min(retry*2, listenRetryMax)
+	}
+}
+
+func (s *KeepStorage) listen(ctx context.Context) error {
+	pooled, err := s.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// Соединение с LISTEN не возвращается в пул.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{changesChannel}.Sanitize()); err != nil {
+		return err
+	}
+	s.zlog.Debug().Msg("Listening for changes")
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var b events.Batch
+		if err := json.Unmarshal([]byte(n.Payload), &b); err != nil {
+			s.zlog.Error().Err(err).Msg("Invalid change notification")
+			continue
+		}
+		s.changes.dispatch(b)
+	}
+}
+
+// publishChanges - увеличение ревизии пользователя и уведомление об изменениях
+// в транзакции tx; уведомление доставляется после ее фиксации.
+func publishChanges(ctx context.Context, q *queries.Queries, uID int, device string, changes []events.Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	rev, err := q.BumpRevision(ctx, uID)
+	if err != nil {
+		return err
+	}
+	b := events.Batch{UserID: uID, Device: device, Revision: rev, Changes: changes}
+	payload, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		b.Changes, b.Resync = nil, true
+		if payload, err = json.Marshal(b); err != nil {
+			return err
+		}
+	}
+	return q.Notify(ctx, changesChannel, string(payload))
+}
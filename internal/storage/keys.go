@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+	"github.com/Dorrrke/GophKeeper-server/internal/storage/queries"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxKeyBundles - предел числа оберток ключа одного пользователя.
+const maxKeyBundles = 16
+
+// KeyBundles - обернутые ключи пользователя.
+func (s *KeepStorage) KeyBundles(ctx context.Context, uID int) ([]models.KeyBundle, error) {
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	bundles, err := q.ListKeyBundles(ctx, uID)
+	if err != nil {
+		s.zlog.Error().Err(err).Msg("List key bundles error")
+		return nil, err
+	}
+	return bundles, tx.Commit(ctx)
+}
+
+// PutKeyBundle - создание или замена обертки с тем же именем.
+func (s *KeepStorage) PutKeyBundle(ctx context.Context, uID int, b models.KeyBundle) (models.KeyBundle, error) {
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{})
+	if err != nil {
+		return models.KeyBundle{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	stored, err := putKeyBundle(ctx, q, uID, b)
+	if err != nil {
+		return models.KeyBundle{}, err
+	}
+	return stored, tx.Commit(ctx)
+}
+
+// DeleteKeyBundle - удаление обертки по имени.
+func (s *KeepStorage) DeleteKeyBundle(ctx context.Context, uID int, name string) error {
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	ok, err := q.DeleteKeyBundle(ctx, uID, name)
+	if err != nil {
+		s.zlog.Error().Err(err).Msg("Delete key bundle error")
+		return err
+	}
+	if !ok {
+		return ErrKeyBundleNotExist
+	}
+	return tx.Commit(ctx)
+}
+
+func putKeyBundle(ctx context.Context, q *queries.Queries, uID int, b models.KeyBundle) (models.KeyBundle, error) {
+	n, err := q.CountOtherKeyBundles(ctx, uID, b.Name)
+	if err != nil {
+		return models.KeyBundle{}, err
+	}
+	if n >= maxKeyBundles {
+		return models.KeyBundle{}, ErrTooManyKeyBundles
+	}
+	return q.UpsertKeyBundle(ctx, uID, b)
+}
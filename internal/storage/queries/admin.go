@@ -0,0 +1,101 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+	"github.com/jackc/pgx/v5"
+)
+
+const setMaintenance = `SELECT set_config('app.maintenance', 'on', true)`
+
+// SetMaintenance - отключает политики RLS до конца транзакции. Используется
+// только служебными инструментами.
+func (q *Queries) SetMaintenance(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, setMaintenance)
+	return err
+}
+
+const listUserSummaries = `
+SELECT uId, login, srp_verifier IS NOT NULL AS srp, locked_at, last_login_at
+FROM users
+WHERE $1::text = '' OR login = $1
+ORDER BY uId`
+
+// ListUserSummaries - пользователи по возрастанию id; login ограничивает
+// выборку одним пользователем.
+func (q *Queries) ListUserSummaries(ctx context.Context, login string) ([]models.UserSummary, error) {
+	rows, err := q.db.Query(ctx, listUserSummaries, login)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.UserSummary])
+}
+
+// ItemStats - записи одного типа пользователя.
+type ItemStats struct {
+	UserID     int64      `db:"uid"`
+	Type       string     `db:"type"`
+	Count      int        `db:"count"`
+	LastUpdate *time.Time `db:"last_update"`
+}
+
+var itemStatsSQL = sync.OnceValue(func() string {
+	var parts []string
+	for _, t := range items.Types() {
+		parts = append(parts, fmt.Sprintf(`SELECT uId, '%s' AS type, count(*) FILTER (WHERE NOT deleted) AS count,
+	max(last_update) AS last_update
+FROM %s WHERE $1::integer = 0 OR uId = $1 GROUP BY uId`, t.Name, t.Table))
+	}
+	parts = append(parts, `SELECT uId, '' AS type, 0 AS count, max(created_at) AS last_update
+FROM account_events WHERE $1::integer = 0 OR uId = $1 GROUP BY uId`)
+	return strings.Join(parts, "\nUNION ALL\n")
+})
+
+// ListItemStats - число неудаленных записей и время последнего изменения по
+// типам; строки с пустым Type - последнее событие учетной записи. uID = 0 -
+// все пользователи.
+func (q *Queries) ListItemStats(ctx context.Context, uID int) ([]ItemStats, error) {
+	rows, err := q.db.Query(ctx, itemStatsSQL(), uID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[ItemStats])
+}
+
+const lockUser = `
+UPDATE users SET locked_at = now(), token_version = token_version + 1
+WHERE uId = $1 AND locked_at IS NULL`
+
+// LockUser - блокировка учетной записи с отзывом выданных токенов.
+func (q *Queries) LockUser(ctx context.Context, uID int) (bool, error) {
+	tag, err := q.db.Exec(ctx, lockUser, uID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+const unlockUser = `UPDATE users SET locked_at = NULL WHERE uId = $1 AND locked_at IS NOT NULL`
+
+func (q *Queries) UnlockUser(ctx context.Context, uID int) (bool, error) {
+	tag, err := q.db.Exec(ctx, unlockUser, uID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+const revokeTokens = `UPDATE users SET token_version = token_version + 1 WHERE uId = $1 RETURNING token_version`
+
+// RevokeTokens - отзыв всех выданных токенов пользователя.
+func (q *Queries) RevokeTokens(ctx context.Context, uID int) (int, error) {
+	var version int
+	err := q.db.QueryRow(ctx, revokeTokens, uID).Scan(&version)
+	return version, err
+}
@@ -0,0 +1,110 @@
+package queries
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const schemaVersion = `SELECT version, dirty FROM schema_migrations LIMIT 1`
+
+// SchemaVersion - версия миграций базы.
+func (q *Queries) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := q.db.QueryRow(ctx, schemaVersion).Scan(&version, &dirty)
+	return uint(version), dirty, err
+}
+
+// DumpTable - все строки таблицы в виде JSON-объектов колонок.
+func (q *Queries) DumpTable(ctx context.Context, table string, fn func(row []byte) error) error {
+	ident := pgx.Identifier{table}.Sanitize()
+	rows, err := q.db.Query(ctx, fmt.Sprintf(`SELECT row_to_json(t)::text FROM %s t`, ident))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var row []byte
+		if err := rows.Scan(&row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// InsertJSONRow - вставка строки из JSON-объекта колонок. Колонки, которых
+// нет в объекте, получают значения по умолчанию. Если returning не пуст,
+// возвращается значение этой колонки.
+func (q *Queries) InsertJSONRow(ctx context.Context, table string, row map[string]json.RawMessage,
+	returning string) (int64, error) {
+	cols := make([]string, 0, len(row))
+	for name := range row {
+		cols = append(cols, pgx.Identifier{name}.Sanitize())
+	}
+	sort.Strings(cols)
+	list := strings.Join(cols, ", ")
+	ident := pgx.Identifier{table}.Sanitize()
+	sql := fmt.Sprintf(`INSERT INTO %s (%s) SELECT %s FROM json_populate_record(NULL::%s, $1::json)`,
+		ident, list, list, ident)
+	data, err := json.Marshal(row)
+	if err != nil {
+		return 0, err
+	}
+	if returning == "" {
+		_, err := q.db.Exec(ctx, sql, data)
+		return 0, err
+	}
+	var id int64
+	err = q.db.QueryRow(ctx, sql+" RETURNING "+pgx.Identifier{returning}.Sanitize(), data).Scan(&id)
+	return id, err
+}
+
+const serialColumns = `
+SELECT column_name::text
+FROM information_schema.columns
+WHERE table_schema = current_schema() AND table_name = $1
+	AND pg_get_serial_sequence(quote_ident($1), column_name) IS NOT NULL
+ORDER BY ordinal_position`
+
+// SerialColumns - колонки таблицы, заполняемые собственной последовательностью.
+func (q *Queries) SerialColumns(ctx context.Context, table string) ([]string, error) {
+	rows, err := q.db.Query(ctx, serialColumns, table)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// ResetSequences - продолжение последовательностей serial-колонок таблицы
+// после наибольшего значения в ней.
+func (q *Queries) ResetSequences(ctx context.Context, table string) error {
+	cols, err := q.SerialColumns(ctx, table)
+	if err != nil {
+		return err
+	}
+	ident := pgx.Identifier{table}.Sanitize()
+	for _, col := range cols {
+		sql := fmt.Sprintf(`SELECT setval(pg_get_serial_sequence(quote_ident($1), $2),
+	COALESCE((SELECT max(%s) FROM %s), 0) + 1, false)`, pgx.Identifier{col}.Sanitize(), ident)
+		if _, err := q.db.Exec(ctx, sql, table, col); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const countUsers = `SELECT count(*) FROM users`
+
+func (q *Queries) CountUsers(ctx context.Context) (int, error) {
+	var n int
+	err := q.db.QueryRow(ctx, countUsers).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,20 @@
+package queries
+
+import "context"
+
+const bumpRevision = `UPDATE users SET revision = revision + 1 WHERE uId = $1 RETURNING revision`
+
+// BumpRevision - увеличение ревизии хранилища пользователя.
+func (q *Queries) BumpRevision(ctx context.Context, uID int) (int64, error) {
+	var rev int64
+	err := q.db.QueryRow(ctx, bumpRevision, uID).Scan(&rev)
+	return rev, err
+}
+
+const notify = `SELECT pg_notify($1, $2)`
+
+// Notify - отправка уведомления в канал; доставляется после фиксации транзакции.
+func (q *Queries) Notify(ctx context.Context, channel, payload string) error {
+	_, err := q.db.Exec(ctx, notify, channel, payload)
+	return err
+}
@@ -0,0 +1,52 @@
+// Package queries - типизированный слой запросов к базе данных.
+// Все значения передаются только связанными параметрами, результаты
+// сканируются в модели по тегам `db` или по описанию типа записи.
+package queries
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DBTX - общий интерфейс пула соединений и транзакции.
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, rows pgx.CopyFromSource) (int64, error)
+}
+
+type Queries struct {
+	db DBTX
+}
+
+func New(db DBTX) *Queries {
+	return &Queries{db: db}
+}
+
+const setUserContext = `SELECT set_config('app.user_id', $1, true)`
+
+// SetUserContext - выставляет пользователя текущей транзакции для политик RLS.
+func (q *Queries) SetUserContext(ctx context.Context, uID int) error {
+	_, err := q.db.Exec(ctx, setUserContext, strconv.Itoa(uID))
+	return err
+}
+
+func collectOneRow[T any](rows pgx.Rows, err error) (T, error) {
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[T])
+}
+
+// nonNil - пустой срез вместо nil, чтобы `<> ALL($n)` не получил NULL.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,88 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// emergencySelect - контакт с логинами сторон. Доступ открыт, если запрос
+// не отклонен за wait_seconds; время берется из базы.
+const emergencySelect = `
+SELECT ec.id::text AS id, ec.owner_id, o.login AS owner_login,
+	ec.contact_id, c.login AS contact_login, ec.wait_seconds, ec.requested_at,
+	COALESCE(ec.requested_at + make_interval(secs => ec.wait_seconds) <= now(), false) AS granted
+FROM emergency_contacts ec
+JOIN users o ON o.uId = ec.owner_id
+JOIN users c ON c.uId = ec.contact_id`
+
+const insertEmergencyContact = `
+INSERT INTO emergency_contacts (owner_id, contact_id, wait_seconds)
+VALUES ($1, $2, $3)
+RETURNING id::text`
+
+func (q *Queries) InsertEmergencyContact(ctx context.Context, ownerID, contactID int, waitSeconds int64) (string, error) {
+	var id string
+	err := q.db.QueryRow(ctx, insertEmergencyContact, ownerID, contactID, waitSeconds).Scan(&id)
+	return id, err
+}
+
+const getEmergencyContact = emergencySelect + ` WHERE ec.id = $1`
+
+func (q *Queries) GetEmergencyContact(ctx context.Context, id string) (models.EmergencyContact, error) {
+	rows, err := q.db.Query(ctx, getEmergencyContact, id)
+	return collectOneRow[models.EmergencyContact](rows, err)
+}
+
+const listEmergencyContacts = emergencySelect + `
+WHERE ec.owner_id = $1 OR ec.contact_id = $1
+ORDER BY ec.created_at`
+
+// ListEmergencyContacts - контакты пользователя и владельцы, у которых он
+// указан контактом.
+func (q *Queries) ListEmergencyContacts(ctx context.Context, uID int) ([]models.EmergencyContact, error) {
+	rows, err := q.db.Query(ctx, listEmergencyContacts, uID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.EmergencyContact])
+}
+
+const deleteEmergencyContact = `DELETE FROM emergency_contacts WHERE id = $1 AND owner_id = $2`
+
+func (q *Queries) DeleteEmergencyContact(ctx context.Context, ownerID int, id string) (bool, error) {
+	tag, err := q.db.Exec(ctx, deleteEmergencyContact, id, ownerID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+const requestEmergencyAccess = `
+UPDATE emergency_contacts SET requested_at = now()
+WHERE id = $1 AND contact_id = $2 AND requested_at IS NULL`
+
+// RequestEmergencyAccess - запрос доступа контактом; повторный запрос
+// не сбрасывает время ожидания.
+func (q *Queries) RequestEmergencyAccess(ctx context.Context, contactID int, id string) (bool, error) {
+	tag, err := q.db.Exec(ctx, requestEmergencyAccess, id, contactID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+const denyEmergencyAccess = `
+UPDATE emergency_contacts SET requested_at = NULL
+WHERE id = $1 AND owner_id = $2 AND requested_at IS NOT NULL`
+
+// DenyEmergencyAccess - отклонение запроса владельцем, в том числе уже
+// открытого доступа.
+func (q *Queries) DenyEmergencyAccess(ctx context.Context, ownerID int, id string) (bool, error) {
+	tag, err := q.db.Exec(ctx, denyEmergencyAccess, id, ownerID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
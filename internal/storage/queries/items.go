@@ -0,0 +1,335 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+	"github.com/jackc/pgx/v5"
+)
+
+// itemSQL - запросы для одного типа записи, построенные по его описанию.
+// Имена таблиц и колонок берутся только из реестра типов, значения
+// передаются параметрами.
+type itemSQL struct {
+	upsert           string
+	findIDs          string
+	existingIDs      string
+	get              string
+	getByIDs         string
+	listExcept       string
+	deleteTombstones string
+	deleteAll        string
+
+	// stageTable - временная таблица синхронизации, stageColumns - ее
+	// колонки для COPY.
+	stageTable   string
+	stageColumns []string
+	createStage  string
+	mergeStage   string
+	listStaged   string
+}
+
+var itemSQLCache sync.Map
+
+func statements(t *items.Type) *itemSQL {
+	if st, ok := itemSQLCache.Load(t); ok {
+		return st.(*itemSQL)
+	}
+	st, _ := itemSQLCache.LoadOrStore(t, buildItemSQL(t))
+	return st.(*itemSQL)
+}
+
+func buildItemSQL(t *items.Type) *itemSQL {
+	// Колонки в порядке параметров: id, name, поля типа, метаданные,
+	// uId, deleted, last_update. Поля ReadOnly только читаются. Колонки
+	// с cast хранятся во временной таблице как text и приводятся при слиянии.
+	type column struct {
+		name, param, sel, cast string
+		readOnly               bool
+	}
+	cols := []column{{name: "id", cast: "uuid"}, {name: "name"}}
+	for _, f := range t.Fields {
+		c := column{name: f.Column, readOnly: f.ReadOnly}
+		if f.SQLType != "" {
+			c.param = "::text::" + f.SQLType
+			c.sel = fmt.Sprintf("%s::text AS %s", f.Column, f.Column)
+			c.cast = f.SQLType
+		}
+		cols = append(cols, c)
+	}
+	if t.Meta {
+		cols = append(cols, column{name: "folder_id", cast: "uuid"}, column{name: "tags"}, column{name: "custom_fields"})
+	}
+	cols = append(cols, column{name: "uId"}, column{name: "deleted"}, column{name: "last_update"})
+
+	var insertCols, values, selectCols, updates, stageCols, stageSel, stageVals []string
+	for _, c := range cols {
+		if c.sel == "" {
+			c.sel = c.name
+		}
+		selectCols = append(selectCols, c.sel)
+		if c.readOnly {
+			continue
+		}
+		insertCols = append(insertCols, c.name)
+		values = append(values, fmt.Sprintf("$%d%s", len(values)+1, c.param))
+		if c.name != "id" && c.name != "uId" {
+			updates = append(updates, fmt.Sprintf("%s = excluded.%s", c.name, c.name))
+		}
+		stageCols = append(stageCols, strings.ToLower(c.name))
+		if c.cast != "" {
+			stageSel = append(stageSel, fmt.Sprintf("%s::text AS %s", c.name, c.name))
+			stageVals = append(stageVals, fmt.Sprintf("s.%s::%s", c.name, c.cast))
+		} else {
+			stageSel = append(stageSel, c.name)
+			stageVals = append(stageVals, "s."+c.name)
+		}
+	}
+	stageCols = append(stageCols, "assigned")
+
+	sel := strings.Join(selectCols, ", ")
+	stage := "sync_stage_" + t.Table
+	onConflict := fmt.Sprintf(`ON CONFLICT (id) DO UPDATE SET %s
+WHERE t.uId = excluded.uId AND t.last_update < excluded.last_update`, strings.Join(updates, ", "))
+	return &itemSQL{
+		upsert: fmt.Sprintf(`INSERT INTO %s AS t (%s) VALUES (%s)
+%s`,
+			t.Table, strings.Join(insertCols, ", "), strings.Join(values, ", "), onConflict),
+		findIDs: fmt.Sprintf(`SELECT DISTINCT ON (name) name, id::text FROM %s
+WHERE uId = $1 AND name = ANY($2) ORDER BY name, last_update DESC`,
+			t.Table),
+		existingIDs: fmt.Sprintf(`SELECT id::text FROM %s WHERE uId = $1 AND id = ANY($2::uuid[])`,
+			t.Table),
+		get: fmt.Sprintf(`SELECT %s FROM %s WHERE uId = $1 AND id = $2`,
+			sel, t.Table),
+		getByIDs: fmt.Sprintf(`SELECT %s FROM %s WHERE uId = $1 AND id = ANY($2)`,
+			sel, t.Table),
+		listExcept: fmt.Sprintf(`SELECT %s FROM %s WHERE uId = $1 AND id <> ALL($2)`,
+			sel, t.Table),
+		deleteTombstones: fmt.Sprintf(`DELETE FROM %s WHERE uId = $1 AND deleted`, t.Table),
+		deleteAll:        fmt.Sprintf(`DELETE FROM %s WHERE uId = $1`, t.Table),
+
+		stageTable:   stage,
+		stageColumns: stageCols,
+		createStage: fmt.Sprintf(`CREATE TEMP TABLE %s ON COMMIT DROP AS
+SELECT %s, false AS assigned FROM %s WITH NO DATA`,
+			stage, strings.Join(stageSel, ", "), t.Table),
+		mergeStage: fmt.Sprintf(`INSERT INTO %s AS t (%s)
+SELECT %s FROM %s s
+%s
+RETURNING t.id::text, t.deleted`,
+			t.Table, strings.Join(insertCols, ", "), strings.Join(stageVals, ", "), stage, onConflict),
+		// Записи, отсутствующие у клиента, записи с назначенным сервером id
+		// и записи, версия которых на сервере новее клиентской.
+		listStaged: fmt.Sprintf(`SELECT %s FROM %s t
+WHERE uId = $1 AND NOT EXISTS (
+    SELECT 1 FROM %s s
+    WHERE s.id::uuid = t.id AND NOT s.assigned AND t.last_update <= s.last_update)`,
+			sel, t.Table, stage),
+	}
+}
+
+// DeleteTombstones - удаление помеченных на удаление записей пользователя.
+func (q *Queries) DeleteTombstones(ctx context.Context, uID int) error {
+	for _, t := range items.Types() {
+		if _, err := q.db.Exec(ctx, statements(t).deleteTombstones, uID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpsertItem - вставка записи или ее обновление, если пришедшая версия новее.
+// Запись другого пользователя с тем же id не изменяется. Возвращает true,
+// если строка была вставлена или обновлена.
+func (q *Queries) UpsertItem(ctx context.Context, t *items.Type, it items.Item) (bool, error) {
+	tag, err := q.db.Exec(ctx, statements(t).upsert, itemArgs(t, it)...)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// itemArgs - значения записываемых колонок записи в порядке параметров.
+func itemArgs(t *items.Type, it items.Item) []any {
+	args := make([]any, 0, len(t.Fields)+9)
+	args = append(args, it.ID, it.Name)
+	for _, f := range t.Fields {
+		if !f.ReadOnly {
+			args = append(args, it.Values[f.Name])
+		}
+	}
+	if t.Meta {
+		var folderID *string
+		if it.FolderID != "" {
+			folderID = &it.FolderID
+		}
+		customFields := it.CustomFields
+		if customFields == nil {
+			customFields = []items.CustomField{}
+		}
+		args = append(args, folderID, nonNil(it.Tags), customFields)
+	}
+	return append(args, it.UserID, it.Deleted, it.Updated)
+}
+
+// FindItemIDs - id самых свежих записей с такими именами по имени, для
+// клиентов без id.
+func (q *Queries) FindItemIDs(ctx context.Context, t *items.Type, uID int, names []string) (map[string]string, error) {
+	rows, err := q.db.Query(ctx, statements(t).findIDs, uID, nonNil(names))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make(map[string]string, len(names))
+	for rows.Next() {
+		var name, id string
+		if err := rows.Scan(&name, &id); err != nil {
+			return nil, err
+		}
+		ids[name] = id
+	}
+	return ids, rows.Err()
+}
+
+// ExistingItemIDs - те из ids, которые принадлежат записям пользователя.
+func (q *Queries) ExistingItemIDs(ctx context.Context, t *items.Type, uID int, ids []string) (map[string]bool, error) {
+	rows, err := q.db.Query(ctx, statements(t).existingIDs, uID, nonNil(ids))
+	if err != nil {
+		return nil, err
+	}
+	found, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]bool, len(found))
+	for _, id := range found {
+		res[id] = true
+	}
+	return res, nil
+}
+
+// GetItem - запись по id.
+func (q *Queries) GetItem(ctx context.Context, t *items.Type, uID int, id string) (items.Item, error) {
+	rows, err := q.db.Query(ctx, statements(t).get, uID, id)
+	if err != nil {
+		return items.Item{}, err
+	}
+	return pgx.CollectOneRow(rows, rowToItem(t))
+}
+
+// ListItemsExcept - записи пользователя, отсутствующие у клиента.
+func (q *Queries) ListItemsExcept(ctx context.Context, t *items.Type, uID int, ids []string) ([]items.Item, error) {
+	rows, err := q.db.Query(ctx, statements(t).listExcept, uID, nonNil(ids))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, rowToItem(t))
+}
+
+// StageItems - загрузка записей клиента во временную таблицу синхронизации
+// одним COPY. Таблица удаляется при завершении транзакции; assigned -
+// id, назначенные сервером записям клиента без id.
+func (q *Queries) StageItems(ctx context.Context, t *items.Type, list []items.Item, assigned map[string]bool) error {
+	st := statements(t)
+	if _, err := q.db.Exec(ctx, st.createStage); err != nil {
+		return err
+	}
+	rows := make([][]any, 0, len(list))
+	for _, it := range list {
+		rows = append(rows, append(itemArgs(t, it), assigned[it.ID]))
+	}
+	_, err := q.db.CopyFrom(ctx, pgx.Identifier{st.stageTable}, st.stageColumns, pgx.CopyFromRows(rows))
+	return err
+}
+
+// ChangedItem - запись, вставленная или обновленная слиянием.
+type ChangedItem struct {
+	ID      string
+	Deleted bool
+}
+
+// MergeStagedItems - слияние записей временной таблицы с таблицей типа
+// по правилам UpsertItem одним запросом. id во временной таблице должны
+// быть уникальны.
+func (q *Queries) MergeStagedItems(ctx context.Context, t *items.Type) ([]ChangedItem, error) {
+	rows, err := q.db.Query(ctx, statements(t).mergeStage)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (ChangedItem, error) {
+		var c ChangedItem
+		err := row.Scan(&c.ID, &c.Deleted)
+		return c, err
+	})
+}
+
+// ListStagedItems - записи, которые нужно вернуть клиенту после слияния:
+// отсутствующие у него, с назначенным сервером id и более новые на сервере.
+func (q *Queries) ListStagedItems(ctx context.Context, t *items.Type, uID int) ([]items.Item, error) {
+	rows, err := q.db.Query(ctx, statements(t).listStaged, uID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, rowToItem(t))
+}
+
+const missingFolders = `
+SELECT f::text FROM unnest($2::uuid[]) AS f
+WHERE NOT EXISTS (SELECT 1 FROM folders WHERE uId = $1 AND id = f)`
+
+// MissingFolders - id из списка, которые не являются папками пользователя.
+func (q *Queries) MissingFolders(ctx context.Context, uID int, ids []string) ([]string, error) {
+	rows, err := q.db.Query(ctx, missingFolders, uID, nonNil(ids))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// rowToItem - сканирование строки в запись по описанию типа.
+func rowToItem(t *items.Type) pgx.RowToFunc[items.Item] {
+	return func(row pgx.CollectableRow) (items.Item, error) {
+		it := items.Item{Type: t.Name, Values: make(map[string]any, len(t.Fields))}
+		strs := make([]string, len(t.Fields))
+		bins := make([][]byte, len(t.Fields))
+		ints := make([]int64, len(t.Fields))
+		var folderID *string
+		dest := make([]any, 0, len(t.Fields)+8)
+		dest = append(dest, &it.ID, &it.Name)
+		for i, f := range t.Fields {
+			switch f.Kind {
+			case items.KindBytes:
+				dest = append(dest, &bins[i])
+			case items.KindInt64:
+				dest = append(dest, &ints[i])
+			default:
+				dest = append(dest, &strs[i])
+			}
+		}
+		if t.Meta {
+			dest = append(dest, &folderID, &it.Tags, &it.CustomFields)
+		}
+		dest = append(dest, &it.UserID, &it.Deleted, &it.Updated)
+		if err := row.Scan(dest...); err != nil {
+			return items.Item{}, err
+		}
+		if folderID != nil {
+			it.FolderID = *folderID
+		}
+		for i, f := range t.Fields {
+			switch f.Kind {
+			case items.KindBytes:
+				it.Values[f.Name] = bins[i]
+			case items.KindInt64:
+				it.Values[f.Name] = ints[i]
+			default:
+				it.Values[f.Name] = strs[i]
+			}
+		}
+		return it, nil
+	}
+}
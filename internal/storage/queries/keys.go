@@ -0,0 +1,63 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+	"github.com/jackc/pgx/v5"
+)
+
+const listKeyBundles = `
+SELECT name, algorithm, wrapped_key, params, last_update
+FROM key_bundles WHERE uId = $1
+ORDER BY name`
+
+func (q *Queries) ListKeyBundles(ctx context.Context, uID int) ([]models.KeyBundle, error) {
+	rows, err := q.db.Query(ctx, listKeyBundles, uID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.KeyBundle])
+}
+
+const countOtherKeyBundles = `SELECT count(*) FROM key_bundles WHERE uId = $1 AND name <> $2`
+
+// CountOtherKeyBundles - число оберток пользователя с именем, отличным от name.
+func (q *Queries) CountOtherKeyBundles(ctx context.Context, uID int, name string) (int, error) {
+	var n int
+	err := q.db.QueryRow(ctx, countOtherKeyBundles, uID, name).Scan(&n)
+	return n, err
+}
+
+const upsertKeyBundle = `
+INSERT INTO key_bundles (uId, name, algorithm, wrapped_key, params, last_update)
+VALUES ($1, $2, $3, $4, $5, now())
+ON CONFLICT (uId, name) DO UPDATE SET
+	algorithm = excluded.algorithm,
+	wrapped_key = excluded.wrapped_key,
+	params = excluded.params,
+	last_update = excluded.last_update
+RETURNING name, algorithm, wrapped_key, params, last_update`
+
+func (q *Queries) UpsertKeyBundle(ctx context.Context, uID int, b models.KeyBundle) (models.KeyBundle, error) {
+	rows, err := q.db.Query(ctx, upsertKeyBundle, uID, b.Name, b.Algorithm, b.WrappedKey, nonNilBytes(b.Params))
+	return collectOneRow[models.KeyBundle](rows, err)
+}
+
+const deleteKeyBundle = `DELETE FROM key_bundles WHERE uId = $1 AND name = $2`
+
+// DeleteKeyBundle - удаление обертки; возвращает false, если ее не было.
+func (q *Queries) DeleteKeyBundle(ctx context.Context, uID int, name string) (bool, error) {
+	tag, err := q.db.Exec(ctx, deleteKeyBundle, uID, name)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func nonNilBytes(b []byte) []byte {
+	if b == nil {
+		return []byte{}
+	}
+	return b
+}
@@ -0,0 +1,108 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+	"github.com/jackc/pgx/v5"
+)
+
+// listSubquery - выборка метаданных одного типа. Параметры:
+// $1 uId, $2 префикс имени, $3 подстрока имени, $4 строка поиска,
+// $5 тег, $6 папка, $7 изменено после, $8 вместе с удаленными.
+func listSubquery(t *items.Type) string {
+	var attrs []string
+	for _, f := range t.Fields {
+		if f.Secret || f.Kind != items.KindString {
+			continue
+		}
+		col := f.Column
+		if f.SQLType != "" {
+			col += "::text"
+		}
+		attrs = append(attrs, fmt.Sprintf("'%s', %s", f.Name, col))
+	}
+	attrExpr := "'{}'::jsonb"
+	if len(attrs) > 0 {
+		attrExpr = fmt.Sprintf("jsonb_build_object(%s)", strings.Join(attrs, ", "))
+	}
+
+	folderExpr, tagsExpr := "NULL::text", "'{}'::text[]"
+	tagCond, folderCond, tagSearch := "$5::text = ''", "$6::uuid IS NULL", ""
+	if t.Meta {
+		folderExpr, tagsExpr = "folder_id::text", "tags"
+		tagCond, folderCond = "($5::text = '' OR $5 = ANY(tags))", "($6::uuid IS NULL OR folder_id = $6)"
+		tagSearch = " OR EXISTS (SELECT 1 FROM unnest(tags) AS tg WHERE strpos(lower(tg), lower($4)) > 0)"
+	}
+
+	return fmt.Sprintf(`SELECT id::text AS id, '%s'::text AS type, name::text AS name,
+	%s AS folder_id, %s AS tags, %s AS attributes, deleted, last_update
+FROM %s
+WHERE uId = $1
+	AND ($2::text = '' OR left(name, length($2)) = $2)
+	AND ($3::text = '' OR strpos(lower(name), lower($3)) > 0)
+	AND ($4::text = '' OR strpos(lower(name), lower($4)) > 0%s)
+	AND %s
+	AND %s
+	AND ($7::timestamptz IS NULL OR last_update > $7)
+	AND ($8::boolean OR NOT deleted)`,
+		t.Name, folderExpr, tagsExpr, attrExpr, t.Table, tagSearch, tagCond, folderCond)
+}
+
+// ListItemMeta - страница метаданных записей, упорядоченных по имени, типу и id,
+// начиная после позиции after.
+func (q *Queries) ListItemMeta(ctx context.Context, uID int, f items.Filter, after *items.Cursor, limit int) ([]items.Meta, error) {
+	if len(f.Types) == 0 {
+		return nil, nil
+	}
+	subs := make([]string, 0, len(f.Types))
+	for _, t := range f.Types {
+		subs = append(subs, listSubquery(t))
+	}
+	query := fmt.Sprintf(`SELECT id, type, name, folder_id, tags, attributes, deleted, last_update
+FROM (%s) AS i
+WHERE ($9::text IS NULL OR (name, type, id) > ($9, $10, $11))
+ORDER BY name, type, id
+LIMIT $12`, strings.Join(subs, "\nUNION ALL\n"))
+
+	var folderID *string
+	if f.FolderID != "" {
+		folderID = &f.FolderID
+	}
+	var since *time.Time
+	if !f.ModifiedSince.IsZero() {
+		since = &f.ModifiedSince
+	}
+	var afterName *string
+	var afterType, afterID string
+	if after != nil {
+		afterName, afterType, afterID = &after.Name, after.Type, after.ID
+	}
+
+	rows, err := q.db.Query(ctx, query, uID, f.NamePrefix, f.NameContains, f.Query, f.Tag, folderID, since,
+		f.WithDeleted, afterName, afterType, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (items.Meta, error) {
+		var m items.Meta
+		var folder *string
+		err := row.Scan(&m.ID, &m.Type, &m.Name, &folder, &m.Tags, &m.Attributes, &m.Deleted, &m.Updated)
+		if folder != nil {
+			m.FolderID = *folder
+		}
+		return m, err
+	})
+}
+
+// GetItemsByIDs - записи типа t с указанными id.
+func (q *Queries) GetItemsByIDs(ctx context.Context, t *items.Type, uID int, ids []string) ([]items.Item, error) {
+	rows, err := q.db.Query(ctx, statements(t).getByIDs, uID, nonNil(ids))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, rowToItem(t))
+}
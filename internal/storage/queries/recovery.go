@@ -0,0 +1,81 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+	"github.com/jackc/pgx/v5"
+)
+
+const insertRecoveryCodes = `INSERT INTO recovery_codes (uId, code_hash) SELECT $1, unnest($2::bytea[])`
+
+func (q *Queries) InsertRecoveryCodes(ctx context.Context, uID int, hashes [][]byte) error {
+	_, err := q.db.Exec(ctx, insertRecoveryCodes, uID, hashes)
+	return err
+}
+
+const deleteRecoveryCodes = `DELETE FROM recovery_codes WHERE uId = $1`
+
+func (q *Queries) DeleteRecoveryCodes(ctx context.Context, uID int) error {
+	_, err := q.db.Exec(ctx, deleteRecoveryCodes, uID)
+	return err
+}
+
+const useRecoveryCode = `
+UPDATE recovery_codes SET used_at = now()
+WHERE uId = $1 AND code_hash = $2 AND used_at IS NULL`
+
+// UseRecoveryCode - отметка кода использованным; false, если кода нет
+// или он уже использован.
+func (q *Queries) UseRecoveryCode(ctx context.Context, uID int, hash []byte) (bool, error) {
+	tag, err := q.db.Exec(ctx, useRecoveryCode, uID, hash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+const countRecoveryCodes = `SELECT count(*) FROM recovery_codes WHERE uId = $1 AND used_at IS NULL`
+
+// CountRecoveryCodes - число неиспользованных кодов.
+func (q *Queries) CountRecoveryCodes(ctx context.Context, uID int) (int, error) {
+	var n int
+	err := q.db.QueryRow(ctx, countRecoveryCodes, uID).Scan(&n)
+	return n, err
+}
+
+const insertAccountEvent = `
+INSERT INTO account_events (uId, actor_id, event, details)
+VALUES ($1, $2, $3, $4)`
+
+// InsertAccountEvent - запись события учетной записи uID. actorID - другой
+// пользователь, выполнивший действие, или 0.
+func (q *Queries) InsertAccountEvent(ctx context.Context, uID, actorID int, event string, details map[string]string) error {
+	var actor *int
+	if actorID != 0 {
+		actor = &actorID
+	}
+	if details == nil {
+		details = map[string]string{}
+	}
+	_, err := q.db.Exec(ctx, insertAccountEvent, uID, actor, event, details)
+	return err
+}
+
+const listAccountEvents = `
+SELECT e.id, e.event, COALESCE(a.login, '') AS actor_login, e.details, e.created_at
+FROM account_events e
+LEFT JOIN users a ON a.uId = e.actor_id AND e.actor_id <> e.uId
+WHERE e.uId = $1 AND ($2::bigint = 0 OR e.id < $2)
+ORDER BY e.id DESC
+LIMIT $3`
+
+// ListAccountEvents - события учетной записи от новых к старым, с id меньше
+// beforeID (0 - с последнего).
+func (q *Queries) ListAccountEvents(ctx context.Context, uID int, beforeID int64, limit int) ([]models.AccountEvent, error) {
+	rows, err := q.db.Query(ctx, listAccountEvents, uID, beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.AccountEvent])
+}
@@ -0,0 +1,45 @@
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/srp"
+)
+
+const deleteExpiredSRPSessions = `DELETE FROM srp_sessions WHERE expires_at <= now()`
+
+func (q *Queries) DeleteExpiredSRPSessions(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, deleteExpiredSRPSessions)
+	return err
+}
+
+const createSRPSession = `
+INSERT INTO srp_sessions (uId, client_public, server_secret, server_public, expires_at)
+VALUES (NULLIF($1, 0), $2, $3, $4, $5)
+RETURNING id::text`
+
+// CreateSRPSession - сохранение первого шага входа; uID 0 - сессия без
+// пользователя.
+func (q *Queries) CreateSRPSession(ctx context.Context, uID int, h srp.Handshake, expires time.Time) (string, error) {
+	var id string
+	err := q.db.QueryRow(ctx, createSRPSession, uID, h.A, h.Secret, h.B, expires).Scan(&id)
+	return id, err
+}
+
+const takeSRPSession = `
+DELETE FROM srp_sessions
+WHERE id = $1 AND expires_at > now()
+RETURNING COALESCE(uId, 0), client_public, server_secret, server_public`
+
+// TakeSRPSession - незавершенный вход по id; сессия удаляется, поэтому
+// доказательство клиента проверяется не более одного раза. uID 0 - сессия
+// без пользователя.
+func (q *Queries) TakeSRPSession(ctx context.Context, id string) (int, srp.Handshake, error) {
+	var (
+		uID int
+		h   srp.Handshake
+	)
+	err := q.db.QueryRow(ctx, takeSRPSession, id).Scan(&uID, &h.A, &h.Secret, &h.B)
+	return uID, h, err
+}
@@ -0,0 +1,148 @@
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const deleteExpiredSyncRequests = `DELETE FROM sync_requests WHERE uId = $1 AND expires_at <= now()`
+
+func (q *Queries) DeleteExpiredSyncRequests(ctx context.Context, uID int) error {
+	_, err := q.db.Exec(ctx, deleteExpiredSyncRequests, uID)
+	return err
+}
+
+const createSyncRequest = `
+INSERT INTO sync_requests (uId, key, request_hash, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (uId, key) DO NOTHING`
+
+// CreateSyncRequest - отметка о начале синхронизации с ключом key. Возвращает
+// false, если ключ уже использован.
+func (q *Queries) CreateSyncRequest(ctx context.Context, uID int, key string, hash []byte, expires time.Time) (bool, error) {
+	tag, err := q.db.Exec(ctx, createSyncRequest, uID, key, hash, expires)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+const getSyncRequest = `
+SELECT request_hash, response, started_at
+FROM sync_requests
+WHERE uId = $1 AND key = $2
+FOR UPDATE`
+
+// GetSyncRequest - хеш запроса, сохраненный ответ (nil, пока синхронизация
+// выполняется) и время начала синхронизации. Строка блокируется до конца
+// транзакции.
+func (q *Queries) GetSyncRequest(ctx context.Context, uID int, key string) ([]byte, []byte, time.Time, error) {
+	var (
+		hash, response []byte
+		started        time.Time
+	)
+	err := q.db.QueryRow(ctx, getSyncRequest, uID, key).Scan(&hash, &response, &started)
+	return hash, response, started, err
+}
+
+const restartSyncRequest = `UPDATE sync_requests SET started_at = now() WHERE uId = $1 AND key = $2`
+
+func (q *Queries) RestartSyncRequest(ctx context.Context, uID int, key string) error {
+	_, err := q.db.Exec(ctx, restartSyncRequest, uID, key)
+	return err
+}
+
+const saveSyncResponse = `UPDATE sync_requests SET response = $3 WHERE uId = $1 AND key = $2`
+
+func (q *Queries) SaveSyncResponse(ctx context.Context, uID int, key string, response []byte) error {
+	_, err := q.db.Exec(ctx, saveSyncResponse, uID, key, response)
+	return err
+}
+
+const deleteSyncRequest = `DELETE FROM sync_requests WHERE uId = $1 AND key = $2 AND response IS NULL`
+
+// DeleteSyncRequest - снятие отметки о начале синхронизации, завершившейся
+// ошибкой; сохраненный ответ не удаляется.
+func (q *Queries) DeleteSyncRequest(ctx context.Context, uID int, key string) error {
+	_, err := q.db.Exec(ctx, deleteSyncRequest, uID, key)
+	return err
+}
+
+const deleteExpiredSyncCursors = `DELETE FROM sync_cursors WHERE uId = $1 AND expires_at <= now()`
+
+func (q *Queries) DeleteExpiredSyncCursors(ctx context.Context, uID int) error {
+	_, err := q.db.Exec(ctx, deleteExpiredSyncCursors, uID)
+	return err
+}
+
+const createSyncCursor = `INSERT INTO sync_cursors (uId, expires_at) VALUES ($1, $2) RETURNING id::text`
+
+func (q *Queries) CreateSyncCursor(ctx context.Context, uID int, expires time.Time) (string, error) {
+	var id string
+	err := q.db.QueryRow(ctx, createSyncCursor, uID, expires).Scan(&id)
+	return id, err
+}
+
+// CursorItem - запись постраничного ответа синхронизации.
+type CursorItem struct {
+	Pos     int
+	Type    string
+	ID      string
+	Name    string
+	Deleted bool
+	Updated time.Time
+}
+
+const addSyncCursorItems = `
+INSERT INTO sync_cursor_items (cursor_id, uId, pos, type, item_id, name, deleted, last_update)
+SELECT $1::uuid, $2, r.pos - 1, r.type, r.item_id::uuid, r.name, r.deleted, r.last_update
+FROM unnest($3::text[], $4::text[], $5::text[], $6::boolean[], $7::timestamptz[])
+    WITH ORDINALITY AS r(type, item_id, name, deleted, last_update, pos)`
+
+// AddSyncCursorItems - записи ответа с позициями 0, 1, ... одним запросом.
+func (q *Queries) AddSyncCursorItems(ctx context.Context, cursorID string, uID int, list []CursorItem) error {
+	types := make([]string, 0, len(list))
+	ids := make([]string, 0, len(list))
+	names := make([]string, 0, len(list))
+	deleted := make([]bool, 0, len(list))
+	updated := make([]time.Time, 0, len(list))
+	for _, it := range list {
+		types = append(types, it.Type)
+		ids = append(ids, it.ID)
+		names = append(names, it.Name)
+		deleted = append(deleted, it.Deleted)
+		updated = append(updated, it.Updated)
+	}
+	_, err := q.db.Exec(ctx, addSyncCursorItems, cursorID, uID, types, ids, names, deleted, updated)
+	return err
+}
+
+const syncCursorExists = `SELECT EXISTS (SELECT 1 FROM sync_cursors WHERE id = $1 AND uId = $2 AND expires_at > now())`
+
+func (q *Queries) SyncCursorExists(ctx context.Context, cursorID string, uID int) (bool, error) {
+	var ok bool
+	err := q.db.QueryRow(ctx, syncCursorExists, cursorID, uID).Scan(&ok)
+	return ok, err
+}
+
+const listSyncCursorItems = `
+SELECT pos, type, item_id::text, name, deleted, last_update
+FROM sync_cursor_items
+WHERE cursor_id = $1 AND uId = $2 AND pos >= $3
+ORDER BY pos
+LIMIT $4`
+
+// ListSyncCursorItems - не более limit записей ответа, начиная с позиции from.
+func (q *Queries) ListSyncCursorItems(ctx context.Context, cursorID string, uID, from, limit int) ([]CursorItem, error) {
+	rows, err := q.db.Query(ctx, listSyncCursorItems, cursorID, uID, from, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (CursorItem, error) {
+		var it CursorItem
+		err := row.Scan(&it.Pos, &it.Type, &it.ID, &it.Name, &it.Deleted, &it.Updated)
+		return it, err
+	})
+}
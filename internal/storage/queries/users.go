@@ -0,0 +1,102 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+)
+
+const createUser = `
+INSERT INTO users (login, hash, srp_salt, srp_verifier)
+VALUES ($1, NULLIF($2, ''), $3, $4)
+RETURNING uId`
+
+func (q *Queries) CreateUser(ctx context.Context, user models.UserModel) (int64, error) {
+	var uID int64
+	err := q.db.QueryRow(ctx, createUser, user.Login, user.Hash, user.SRPSalt, user.SRPVerifier).Scan(&uID)
+	return uID, err
+}
+
+const userColumns = `uId, login, COALESCE(hash, '') AS hash, srp_salt, srp_verifier, token_version,
+	locked_at IS NOT NULL AS locked`
+
+const getUserByLogin = `SELECT ` + userColumns + ` FROM users WHERE login = $1`
+
+func (q *Queries) GetUserByLogin(ctx context.Context, login string) (models.UserModel, error) {
+	rows, err := q.db.Query(ctx, getUserByLogin, login)
+	return collectOneRow[models.UserModel](rows, err)
+}
+
+const getUserByID = `SELECT ` + userColumns + ` FROM users WHERE uId = $1`
+
+func (q *Queries) GetUserByID(ctx context.Context, uID int) (models.UserModel, error) {
+	rows, err := q.db.Query(ctx, getUserByID, uID)
+	return collectOneRow[models.UserModel](rows, err)
+}
+
+const getTokenVersion = `SELECT token_version, locked_at IS NOT NULL FROM users WHERE uId = $1`
+
+func (q *Queries) GetTokenVersion(ctx context.Context, uID int) (int, bool, error) {
+	var version int
+	var locked bool
+	err := q.db.QueryRow(ctx, getTokenVersion, uID).Scan(&version, &locked)
+	return version, locked, err
+}
+
+const updatePassword = `
+UPDATE users SET hash = NULL, srp_salt = $2, srp_verifier = $3, token_version = token_version + 1
+WHERE uId = $1
+RETURNING token_version`
+
+// UpdatePassword - новые соль и верификатор SRP; все выданные токены отзываются.
+func (q *Queries) UpdatePassword(ctx context.Context, uID int, salt, verifier []byte) (int, error) {
+	var version int
+	err := q.db.QueryRow(ctx, updatePassword, uID, salt, verifier).Scan(&version)
+	return version, err
+}
+
+const upgradeToSRP = `
+UPDATE users SET hash = NULL, srp_salt = $2, srp_verifier = $3
+WHERE uId = $1 AND hash IS NOT NULL`
+
+// UpgradeToSRP - замена bcrypt-хеша верификатором SRP того же пароля.
+// Выданные токены остаются действительными.
+func (q *Queries) UpgradeToSRP(ctx context.Context, uID int, salt, verifier []byte) error {
+	_, err := q.db.Exec(ctx, upgradeToSRP, uID, salt, verifier)
+	return err
+}
+
+const updateLastLogin = `UPDATE users SET last_login_at = now() WHERE uId = $1`
+
+func (q *Queries) UpdateLastLogin(ctx context.Context, uID int) error {
+	_, err := q.db.Exec(ctx, updateLastLogin, uID)
+	return err
+}
+
+const updateLogin = `UPDATE users SET login = $2 WHERE uId = $1`
+
+func (q *Queries) UpdateLogin(ctx context.Context, uID int, login string) error {
+	_, err := q.db.Exec(ctx, updateLogin, uID, login)
+	return err
+}
+
+const deleteUser = `DELETE FROM users WHERE uId = $1`
+
+func (q *Queries) DeleteUser(ctx context.Context, uID int) error {
+	_, err := q.db.Exec(ctx, deleteUser, uID)
+	return err
+}
+
+// DeleteUserItems - удаление всех записей пользователя всех типов. Типы
+// удаляются в обратном порядке регистрации: записи раньше папок.
+func (q *Queries) DeleteUserItems(ctx context.Context, uID int) error {
+	types := items.Types()
+	for i := len(types) - 1; i >= 0; i-- {
+		t := types[i]
+		if _, err := q.db.Exec(ctx, statements(t).deleteAll, uID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
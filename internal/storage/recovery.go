@@ -0,0 +1,314 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/recovery"
+	"github.com/Dorrrke/GophKeeper-server/internal/storage/queries"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// EmergencyBundlePrefix - префикс имени обертки ключа, которую владелец
+// оставляет для контакта: "emergency:<логин контакта>".
+const EmergencyBundlePrefix = "emergency:"
+
+// issueRecoveryCodes - замена кодов восстановления пользователя.
+func issueRecoveryCodes(ctx context.Context, q *queries.Queries, uID int, hashes [][]byte) error {
+	if err := q.DeleteRecoveryCodes(ctx, uID); err != nil {
+		return err
+	}
+	if err := q.InsertRecoveryCodes(ctx, uID, hashes); err != nil {
+		return err
+	}
+	return q.InsertAccountEvent(ctx, uID, 0, recovery.EventRecoveryCodesIssued,
+		map[string]string{"count": strconv.Itoa(len(hashes))})
+}
+
+// ReplaceRecoveryCodes - новые коды восстановления вместо всех прежних.
+func (s *KeepStorage) ReplaceRecoveryCodes(ctx context.Context, uID int, hashes [][]byte) error {
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := issueRecoveryCodes(ctx, q, uID, hashes); err != nil {
+		s.zlog.Error().Err(err).Msg("Replace recovery codes error")
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// RecoverAccount - смена пароля по коду восстановления. Код используется
+// однократно; выданные токены отзываются. Возвращает новую версию токенов
+// и число оставшихся кодов.
+func (s *KeepStorage) RecoverAccount(ctx context.Context, uID int, codeHash, salt, verifier []byte,
+	bundles []models.KeyBundle) (int, int, error) {
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{})
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	ok, err := q.UseRecoveryCode(ctx, uID, codeHash)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !ok {
+		return 0, 0, ErrInvalidRecoveryCode
+	}
+	version, err := q.UpdatePassword(ctx, uID, salt, verifier)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, b := range bundles {
+		if _, err := putKeyBundle(ctx, q, uID, b); err != nil {
+			return 0, 0, err
+		}
+	}
+	remaining, err := q.CountRecoveryCodes(ctx, uID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := q.InsertAccountEvent(ctx, uID, 0, recovery.EventRecoveryCodeUsed,
+		map[string]string{"remaining": strconv.Itoa(remaining)}); err != nil {
+		return 0, 0, err
+	}
+	return version, remaining, tx.Commit(ctx)
+}
+
+// AccountEvents - страница журнала событий учетной записи.
+func (s *KeepStorage) AccountEvents(ctx context.Context, uID int, beforeID int64, limit int) ([]models.AccountEvent, error) {
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	list, err := q.ListAccountEvents(ctx, uID, beforeID, limit)
+	if err != nil {
+		s.zlog.Error().Err(err).Msg("List account events error")
+		return nil, err
+	}
+	return list, tx.Commit(ctx)
+}
+
+// AddEmergencyContact - добавление доверенного контакта владельцу ownerID.
+func (s *KeepStorage) AddEmergencyContact(ctx context.Context, ownerID, contactID int,
+	waitSeconds int64) (models.EmergencyContact, error) {
+	tx, q, err := s.beginUserTx(ctx, ownerID, pgx.TxOptions{})
+	if err != nil {
+		return models.EmergencyContact{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	id, err := q.InsertEmergencyContact(ctx, ownerID, contactID, waitSeconds)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return models.EmergencyContact{}, ErrEmergencyContactExists
+		}
+		return models.EmergencyContact{}, err
+	}
+	contact, err := q.GetEmergencyContact(ctx, id)
+	if err != nil {
+		return models.EmergencyContact{}, err
+	}
+	if err := q.InsertAccountEvent(ctx, ownerID, 0, recovery.EventContactAdded, contactDetails(contact)); err != nil {
+		return models.EmergencyContact{}, err
+	}
+	return contact, tx.Commit(ctx)
+}
+
+// RemoveEmergencyContact - удаление контакта владельцем.
+func (s *KeepStorage) RemoveEmergencyContact(ctx context.Context, ownerID int, id string) error {
+	tx, q, err := s.beginUserTx(ctx, ownerID, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	contact, err := getEmergencyContact(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	ok, err := q.DeleteEmergencyContact(ctx, ownerID, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrEmergencyContactNotExist
+	}
+	if err := q.InsertAccountEvent(ctx, ownerID, 0, recovery.EventContactRemoved, contactDetails(contact)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// EmergencyContacts - контакты пользователя и владельцы, у которых он указан
+// контактом.
+func (s *KeepStorage) EmergencyContacts(ctx context.Context, uID int) ([]models.EmergencyContact, error) {
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	list, err := q.ListEmergencyContacts(ctx, uID)
+	if err != nil {
+		s.zlog.Error().Err(err).Msg("List emergency contacts error")
+		return nil, err
+	}
+	return list, tx.Commit(ctx)
+}
+
+// RequestEmergencyAccess - запрос доступа контактом contactID. Запрос
+// записывается в журнал владельца.
+func (s *KeepStorage) RequestEmergencyAccess(ctx context.Context, contactID int, id string) (models.EmergencyContact, error) {
+	tx, q, err := s.beginUserTx(ctx, contactID, pgx.TxOptions{})
+	if err != nil {
+		return models.EmergencyContact{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	contact, err := getEmergencyContact(ctx, q, id)
+	if err != nil {
+		return models.EmergencyContact{}, err
+	}
+	if contact.ContactID != int64(contactID) {
+		return models.EmergencyContact{}, ErrEmergencyContactNotExist
+	}
+	ok, err := q.RequestEmergencyAccess(ctx, contactID, id)
+	if err != nil {
+		return models.EmergencyContact{}, err
+	}
+	if !ok {
+		// Запрос уже ожидает решения или доступ открыт.
+		return contact, tx.Commit(ctx)
+	}
+	if contact, err = q.GetEmergencyContact(ctx, id); err != nil {
+		return models.EmergencyContact{}, err
+	}
+	if err := q.InsertAccountEvent(ctx, int(contact.OwnerID), contactID, recovery.EventAccessRequested,
+		contactDetails(contact)); err != nil {
+		return models.EmergencyContact{}, err
+	}
+	return contact, tx.Commit(ctx)
+}
+
+// DenyEmergencyAccess - отклонение запроса доступа владельцем.
+func (s *KeepStorage) DenyEmergencyAccess(ctx context.Context, ownerID int, id string) error {
+	tx, q, err := s.beginUserTx(ctx, ownerID, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	contact, err := getEmergencyContact(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	if contact.OwnerID != int64(ownerID) {
+		return ErrEmergencyContactNotExist
+	}
+	ok, err := q.DenyEmergencyAccess(ctx, ownerID, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrEmergencyAccessNotRequested
+	}
+	details := contactDetails(contact)
+	details["granted"] = strconv.FormatBool(contact.Granted)
+	if err := q.InsertAccountEvent(ctx, ownerID, 0, recovery.EventAccessDenied, details); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// EmergencyVault - записи владельца для контакта contactID, которому открыт
+// доступ, и обертка ключа, оставленная владельцем для контакта. Каждое
+// обращение записывается в журнал владельца.
+func (s *KeepStorage) EmergencyVault(ctx context.Context, contactID int, id string) (items.Set, []models.KeyBundle, error) {
+	contact, err := s.contactView(ctx, contactID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Записи читаются от имени владельца; условия доступа проверяются
+	// повторно в той же транзакции.
+	ownerID := int(contact.OwnerID)
+	tx, q, err := s.beginUserTx(ctx, ownerID, pgx.TxOptions{IsoLevel: pgx.RepeatableRead})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if contact, err = getEmergencyContact(ctx, q, id); err != nil {
+		return nil, nil, err
+	}
+	if contact.ContactID != int64(contactID) {
+		return nil, nil, ErrEmergencyContactNotExist
+	}
+	if !contact.Granted {
+		return nil, nil, ErrEmergencyAccessNotGranted
+	}
+	set, err := exportItems(ctx, q, ownerID)
+	if err != nil {
+		s.zlog.Error().Err(err).Msg("Export emergency vault error")
+		return nil, nil, err
+	}
+	all, err := q.ListKeyBundles(ctx, ownerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var bundles []models.KeyBundle
+	for _, b := range all {
+		if b.Name == EmergencyBundlePrefix+contact.ContactLogin {
+			bundles = append(bundles, b)
+		}
+	}
+	details := contactDetails(contact)
+	details["items"] = strconv.Itoa(set.Len())
+	if err := q.InsertAccountEvent(ctx, ownerID, contactID, recovery.EventVaultAccessed, details); err != nil {
+		return nil, nil, err
+	}
+	return set, bundles, tx.Commit(ctx)
+}
+
+// contactView - контакт, видимый пользователю uID.
+func (s *KeepStorage) contactView(ctx context.Context, uID int, id string) (models.EmergencyContact, error) {
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return models.EmergencyContact{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	contact, err := getEmergencyContact(ctx, q, id)
+	if err != nil {
+		return models.EmergencyContact{}, err
+	}
+	return contact, tx.Commit(ctx)
+}
+
+func getEmergencyContact(ctx context.Context, q *queries.Queries, id string) (models.EmergencyContact, error) {
+	contact, err := q.GetEmergencyContact(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.EmergencyContact{}, ErrEmergencyContactNotExist
+	}
+	return contact, err
+}
+
+func contactDetails(c models.EmergencyContact) map[string]string {
+	return map[string]string{
+		"contact_id":   c.ID,
+		"owner":        c.OwnerLogin,
+		"contact":      c.ContactLogin,
+		"wait_seconds": strconv.FormatInt(c.WaitSeconds, 10),
+	}
+}
@@ -4,16 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
-	"time"
 
 	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/events"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
 	models "github.com/Dorrrke/GophKeeper-server/internal/domain/models"
-	sqlquere "github.com/Dorrrke/GophKeeper-server/internal/domain/sql"
+	"github.com/Dorrrke/GophKeeper-server/internal/storage/queries"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
-	"golang.org/x/sync/errgroup"
 )
 
 var (
@@ -27,392 +30,430 @@ var (
 	ErrLoginNotExist    = errors.New(errText.LoginNotExistsError)
 	ErrTextNotExist     = errors.New(errText.TextNotExistsError)
 	ErrBinDataNotExist  = errors.New(errText.BinDataNotExistsError)
+	ErrFolderNotExist   = errors.New(errText.FolderNotExistsError)
+	ErrItemNotExist     = errors.New(errText.ItemNotExistsError)
+
+	ErrSRPSessionNotExist = errors.New(errText.SRPSessionNotExistError)
+	ErrKeyBundleNotExist  = errors.New(errText.KeyBundleNotExistError)
+	ErrTooManyKeyBundles  = errors.New(errText.TooManyKeyBundlesError)
+
+	ErrInvalidRecoveryCode         = errors.New(errText.InvalidRecoveryCodeError)
+	ErrEmergencyContactNotExist    = errors.New(errText.EmergencyContactNotExistError)
+	ErrEmergencyContactExists      = errors.New(errText.EmergencyContactExistsError)
+	ErrEmergencyAccessNotRequested = errors.New(errText.EmergencyAccessNotRequestedError)
+	ErrEmergencyAccessNotGranted   = errors.New(errText.EmergencyAccessNotGrantedError)
+
+	ErrConcurrentUpdate = errors.New(errText.ConcurrentUpdateError)
+	ErrItemIDConflict   = errors.New(errText.ItemIDConflictError)
+)
+
+const (
+	uniqueViolation      = "23505"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+	// rlsViolation - строка не проходит политику доступа, например id
+	// записи уже занят записью другого пользователя.
+	rlsViolation = "42501"
 )
 
+// maxSyncAttempts - число попыток синхронизации при конфликте транзакций.
+const maxSyncAttempts = 3
+
 type KeepStorage struct {
-	db   *pgxpool.Pool
-	zlog *zerolog.Logger
+	db      *pgxpool.Pool
+	zlog    *zerolog.Logger
+	changes *hub
 }
 
 func New(pool *pgxpool.Pool, zlog *zerolog.Logger) *KeepStorage {
 	return &KeepStorage{
-		zlog: zlog,
-		db:   pool,
+		zlog:    zlog,
+		db:      pool,
+		changes: newHub(),
 	}
 }
 
-func (s *KeepStorage) SaveUser(ctx context.Context, user models.UserModel) (int64, error) {
-	row := s.db.QueryRow(ctx, "INSERT INTO users(login, hash) VALUES($1, $2) RETURNING  uid", user.Login, user.Hash)
-	var uid int64
-	if err := row.Scan(&uid); err != nil {
-		s.zlog.Debug().Err(err).Msg("Save user into db error")
-		return -1, err
-	}
-	return uid, nil
+// isRLSViolation - строка не прошла политику доступа к строкам.
+func isRLSViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == rlsViolation
 }
 
-func (s *KeepStorage) GetUserHash(ctx context.Context, login string) (int64, string, error) {
-	row := s.db.QueryRow(ctx, "SELECT uId, hash FROM users WHERE login = $1", login)
-	var uID int64
-	var hash string
-	if err := row.Scan(&uID, &hash); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return -1, "", ErrUserNotExist
-		}
-		s.zlog.Debug().Err(err).Msg("Get user hash from db error")
-		return -1, "", err
+// isSerializationFailure - транзакция прервана из-за конфликта
+// с параллельной и может быть повторена.
+func isSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected)
+}
+
+// beginUserTx - начало транзакции от имени пользователя. Политики RLS
+// пропускают только строки с uId, равным app.user_id.
+func (s *KeepStorage) beginUserTx(ctx context.Context, uID int, opts pgx.TxOptions) (pgx.Tx, *queries.Queries, error) {
+	tx, err := s.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	q := queries.New(tx)
+	if err := q.SetUserContext(ctx, uID); err != nil {
+		tx.Rollback(ctx)
+		return nil, nil, err
 	}
-	return uID, strings.TrimSpace(hash), nil
+	return tx, q, nil
 }
 
-func (s *KeepStorage) ClearDB(ctx context.Context, uID int) error {
+// SaveUser - создание пользователя вместе с хешами кодов восстановления.
+func (s *KeepStorage) SaveUser(ctx context.Context, user models.UserModel, codeHashes [][]byte) (int64, error) {
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
-		return err
+		return -1, err
 	}
 	defer tx.Rollback(ctx)
+	q := queries.New(tx)
 
-	if _, err := tx.Prepare(ctx, "authClear", "DELETE FROM logins WHERE deleted = true AND uId = $1"); err != nil {
-		return err
-	}
-	if _, err := tx.Prepare(ctx, "textClear", "DELETE FROM text_data WHERE deleted = true AND uId = $1"); err != nil {
-		return err
+	uid, err := q.CreateUser(ctx, user)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return -1, ErrUserAlredyExist
+		}
+		s.zlog.Debug().Err(err).Msg("Save user into db error")
+		return -1, err
 	}
-	if _, err := tx.Prepare(ctx, "binClear", "DELETE FROM binares_data WHERE deleted = true AND uId = $1"); err != nil {
-		return err
+	if err := q.SetUserContext(ctx, int(uid)); err != nil {
+		return -1, err
 	}
-	if _, err := tx.Prepare(ctx, "cardClear", "DELETE FROM cards WHERE deleted = true AND uId = $1"); err != nil {
-		return err
+	if err := issueRecoveryCodes(ctx, q, int(uid), codeHashes); err != nil {
+		s.zlog.Error().Err(err).Msg("Save recovery codes error")
+		return -1, err
 	}
+	return uid, tx.Commit(ctx)
+}
 
-	if _, err := tx.Exec(ctx, "authClear", uID); err != nil {
-		return err
+// GetUser - пользователь по логину.
+func (s *KeepStorage) GetUser(ctx context.Context, login string) (models.UserModel, error) {
+	user, err := queries.New(s.db).GetUserByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.UserModel{}, ErrUserNotExist
+		}
+		s.zlog.Debug().Err(err).Msg("Get user from db error")
+		return models.UserModel{}, err
 	}
-	if _, err := tx.Exec(ctx, "textClear", uID); err != nil {
-		return err
+	return user, nil
+}
+
+// SyncRequest - ключ идемпотентности синхронизации. Encode сериализует
+// первую страницу ответа и id курсора остальных страниц; ответ сохраняется
+// в той же транзакции, что и записи.
+type SyncRequest struct {
+	Key    string
+	Encode func(page items.Set, cursor string) ([]byte, error)
+}
+
+// SyncDB - синхронизация записей клиента с сервером в одной транзакции
+// REPEATABLE READ: все типы записей видят один снимок базы, а изменения,
+// удаление помеченных на удаление записей, уведомление остальных устройств
+// пользователя (кроме device), курсор остальных страниц ответа и ответ для
+// req фиксируются вместе. Запросы одной транзакции выполняются
+// последовательно, поэтому типы синхронизируются по очереди по возрастанию
+// Order. При конфликте с параллельной транзакцией синхронизация повторяется.
+// Возвращает первую страницу ответа и id курсора, пустой, если страница
+// единственная.
+func (s *KeepStorage) SyncDB(ctx context.Context, set items.Set, uId int, device string, paging SyncPaging,
+	req *SyncRequest) (items.Set, string, error) {
+	s.zlog.Debug().Int("User ID", uId).Msg("Run sync")
+	var (
+		page   items.Set
+		cursor string
+		err    error
+	)
+	for attempt := 1; ; attempt++ {
+		page, cursor, err = s.syncTx(ctx, set, uId, device, paging, req)
+		if !isSerializationFailure(err) || attempt == maxSyncAttempts {
+			break
+		}
+		s.zlog.Debug().Err(err).Int("attempt", attempt).Msg("Retry sync")
 	}
-	if _, err := tx.Exec(ctx, "binClear", uID); err != nil {
-		return err
+	if isSerializationFailure(err) {
+		s.zlog.Warn().Err(err).Int("User ID", uId).Msg("Sync conflict")
+		return nil, "", ErrConcurrentUpdate
 	}
-	if _, err := tx.Exec(ctx, "cardClear", uID); err != nil {
-		return err
+	if err != nil {
+		return nil, "", err
 	}
-
-	return tx.Commit(ctx)
+	s.zlog.Debug().Int("page", page.Len()).Bool("more", cursor != "").Msg("Sync done")
+	return page, cursor, nil
 }
 
-func (s *KeepStorage) SyncDB(
-	ctx context.Context, model models.SyncModel, uId int) (models.SyncModel, error) {
-	s.zlog.Debug().Any("sync data", model).Int("User ID", uId).Msg("Run sync")
-	var sTexts []models.SyncTextDataModel
-	var logins []models.SyncLoginModel
-	var sBins []models.SyncBinaryDataModel
-	var sCards []models.SyncCardModel
-	// textCh := make(chan []models.SyncTextDataModel)
-	group, gCtx := errgroup.WithContext(ctx)
-	// Текст.
-	group.Go(func() error {
-		s.zlog.Debug().Msg("Run text sync")
-		tx, err := s.db.Begin(gCtx)
+func (s *KeepStorage) syncTx(ctx context.Context, set items.Set, uId int, device string, paging SyncPaging,
+	req *SyncRequest) (items.Set, string, error) {
+	tx, q, err := s.beginUserTx(ctx, uId, pgx.TxOptions{IsoLevel: pgx.RepeatableRead})
+	if err != nil {
+		s.zlog.Debug().Err(err).Msg("Begin tx error")
+		return nil, "", err
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		actual  []syncEntry
+		changes []events.Change
+		// replaced - id, назначенные сервером вместо временных id клиента.
+		replaced = make(map[string]string)
+	)
+	for _, t := range sortedTypes() {
+		res, ch, err := s.syncType(ctx, q, t, set[t.Name], uId, replaced)
 		if err != nil {
-			s.zlog.Debug().Err(err).Msg("Begin tx error")
-			return err
+			return nil, "", err
 		}
-		defer tx.Rollback(gCtx)
-		var names []string
-		for _, data := range model.Texts {
-			names = append(names, data.Name)
-			cTag, err := tx.Exec(gCtx, sqlquere.SyncUpdateTextTable,
-				data.Name, data.Data, data.UserID, data.Deleted, data.Updated, data.Name, data.Updated, data.UserID,
-				data.Name, data.Data, data.UserID, data.Deleted, data.Updated,
-				data.Name, data.UserID)
-			if err != nil {
-				s.zlog.Error().Err(err).Msg("Update text table error")
-				return err
-			}
-			s.zlog.Debug().Any("commandTag", cTag).Msg("update text result")
-
-			rows := tx.QueryRow(gCtx, sqlquere.SynceTextTableActual, data.Name, data.Updated)
-			var text models.SyncTextDataModel
-			var updated time.Time
-			err = rows.Scan(&text.Name, &text.Data, &text.UserID, &text.Deleted, &updated)
-			text.Updated = updated.Format(time.RFC3339)
-			if err != nil {
-				if !errors.Is(err, pgx.ErrNoRows) {
-					s.zlog.Error().Err(err).Msg("Scan actual text data error")
-					return err
-				}
-			} else {
-				text.Name = strings.TrimSpace(text.Name)
-				text.Data = strings.TrimSpace(text.Data)
-				sTexts = append(sTexts, text)
-			}
+		for _, it := range res {
+			actual = append(actual, syncEntry{t: t, it: it})
 		}
-		placeholders := make([]string, len(names))
-		for i, name := range names {
-			placeholders[i] = fmt.Sprintf("'%s'", name)
+		changes = append(changes, ch...)
+	}
+	if err := q.DeleteTombstones(ctx, uId); err != nil {
+		s.zlog.Error().Err(err).Msg("Delete tombstones error")
+		return nil, "", err
+	}
+	if err := publishChanges(ctx, q, uId, device, changes); err != nil {
+		s.zlog.Error().Err(err).Msg("Publish changes error")
+		return nil, "", err
+	}
+	n := pageLen(actual, paging)
+	var cursor string
+	if n < len(actual) {
+		if cursor, err = saveSyncCursor(ctx, q, uId, actual[n:], paging.TTL); err != nil {
+			s.zlog.Error().Err(err).Msg("Save sync cursor error")
+			return nil, "", err
 		}
-		query := fmt.Sprintf(sqlquere.SynceNewTextData, strings.Join(placeholders, ","))
-		s.zlog.Debug().Str("queue", query).Msg("Text new data quere")
-		s.zlog.Debug().Any("Names", names).Msg("Check")
-		rows, err := tx.Query(gCtx, query, uId)
+	}
+	page := entriesToSet(actual[:n])
+	if req != nil {
+		response, err := req.Encode(page, cursor)
 		if err != nil {
-			s.zlog.Error().Err(err).Msg("sql query error")
-			return err
+			return nil, "", err
 		}
-		defer rows.Close()
-		for rows.Next() {
-			var data models.SyncTextDataModel
-			var updated time.Time
-			err := rows.Scan(&data.Name, &data.Data, &data.UserID, &data.Deleted, &updated)
-			if err != nil {
-				if !errors.Is(err, pgx.ErrNoRows) {
-					s.zlog.Error().Err(err).Msg("Scan new text data error")
-					return err
-				}
-			} else {
-				data.Updated = updated.Format(time.RFC3339)
-				data.Name = strings.TrimSpace(data.Name)
-				data.Data = strings.TrimSpace(data.Data)
-				s.zlog.Debug().Any("New text", data).Msg("Get new text")
-				sTexts = append(sTexts, data)
-			}
+		if err := q.SaveSyncResponse(ctx, uId, req.Key, response); err != nil {
+			return nil, "", err
 		}
-		s.zlog.Debug().Msg("Sync text end")
-		return tx.Commit(gCtx)
-	})
+	}
+	return page, cursor, tx.Commit(ctx)
+}
 
-	// Логины.
-	group.Go(func() error {
-		s.zlog.Debug().Msg("Run auth sync")
-		tx, err := s.db.Begin(gCtx)
+// syncType - синхронизация записей одного типа: запись более новых версий
+// клиента и выборка записей, которые новее на сервере или отсутствуют у клиента.
+// Новым записям id назначает сервер (см. assignItemIDs); такие записи всегда
+// возвращаются клиенту, чтобы он узнал их id. replaced дополняется
+// назначенными вместо временных id клиента.
+// Записи загружаются во временную таблицу через COPY и сливаются одним
+// запросом, поэтому число обращений к базе не зависит от числа записей.
+// Возвращает также записи, измененные на сервере.
+func (s *KeepStorage) syncType(ctx context.Context, q *queries.Queries, t *items.Type, list []items.Item, uID int,
+	replaced map[string]string) ([]items.Item, []events.Change, error) {
+	s.zlog.Debug().Str("type", t.Name).Int("items", len(list)).Msg("Run type sync")
+	if len(list) == 0 {
+		actual, err := q.ListItemsExcept(ctx, t, uID, nil)
 		if err != nil {
-			s.zlog.Debug().Err(err).Msg("Begin tx error")
-			return err
+			s.zlog.Error().Err(err).Str("type", t.Name).Msg("Select new items error")
 		}
-		defer tx.Rollback(gCtx)
-
-		var names []string
-		for _, data := range model.Auth {
-			names = append(names, data.Name)
-			cTag, err := tx.Exec(gCtx, sqlquere.SyncUpdateAuthTable,
-				data.Name, data.Login, data.Password, data.UserID, data.Deleted, data.Updated, data.Name, data.Updated, data.UserID,
-				data.Name, data.Login, data.Password, data.UserID, data.Deleted, data.Updated, data.Name, data.UserID)
-			if err != nil {
-				s.zlog.Error().Err(err).Msg("Update auth table error")
-				return err
-			}
-			s.zlog.Debug().Any("commandTag", cTag).Msg("update auth result")
-			row := tx.QueryRow(gCtx, sqlquere.SynceLoginsTableActual, data.Name, data.Updated)
-			var login models.SyncLoginModel
-			var updated time.Time
-			err = row.Scan(&login.Name, &login.Login, &login.Password, &login.UserID, &login.Deleted, &updated)
-			if err != nil {
-				if !errors.Is(err, pgx.ErrNoRows) {
-					s.zlog.Error().Err(err).Msg("Scan actual auth data error")
-					return err
-				}
-			} else {
-				login.Updated = updated.Format(time.RFC3339)
-				login.Name = strings.TrimSpace(login.Name)
-				login.Login = strings.TrimSpace(login.Login)
-				login.Password = strings.TrimSpace(login.Password)
-				logins = append(logins, login)
-			}
+		return actual, nil, err
+	}
+	list, assigned, err := assignItemIDs(ctx, q, t, list, uID, replaced)
+	if err != nil {
+		s.zlog.Error().Err(err).Str("type", t.Name).Msg("Find item ids error")
+		return nil, nil, err
+	}
+	if t.Meta {
+		if err := checkFolders(ctx, q, list, uID); err != nil {
+			return nil, nil, err
 		}
-		placeholders := make([]string, len(names))
-		for i, name := range names {
-			placeholders[i] = fmt.Sprintf("'%s'", name)
+	}
+	if err := q.StageItems(ctx, t, latestVersions(list), assigned); err != nil {
+		s.zlog.Error().Err(err).Str("type", t.Name).Msg("Stage items error")
+		return nil, nil, err
+	}
+	merged, err := q.MergeStagedItems(ctx, t)
+	if isRLSViolation(err) {
+		return nil, nil, ErrItemIDConflict
+	}
+	if err != nil {
+		s.zlog.Error().Err(err).Str("type", t.Name).Msg("Merge items error")
+		return nil, nil, err
+	}
+	changes := make([]events.Change, 0, len(merged))
+	for _, m := range merged {
+		changes = append(changes, events.Change{Type: t.Name, ID: m.ID, Deleted: m.Deleted})
+	}
+	actual, err := q.ListStagedItems(ctx, t, uID)
+	if err != nil {
+		s.zlog.Error().Err(err).Str("type", t.Name).Msg("Select actual items error")
+		return nil, nil, err
+	}
+	s.zlog.Debug().Str("type", t.Name).Msg("Type sync end")
+	return actual, changes, nil
+}
+
+// assignItemIDs - копия list с id, назначенными сервером. Записи без id
+// получают id самой свежей записи пользователя с тем же именем или новый
+// UUID, общий для записей с одинаковым именем. Для типов без Opaque id
+// клиента, которого нет среди записей пользователя, считается временным
+// (ссылкой на новую запись в том же запросе) и заменяется новым UUID;
+// замены сохраняются в replaced, и по ним же переписываются ссылки на папки.
+// Возвращает также множество назначенных id.
+func assignItemIDs(ctx context.Context, q *queries.Queries, t *items.Type, list []items.Item, uID int,
+	replaced map[string]string) ([]items.Item, map[string]bool, error) {
+	var names, clientIDs []string
+	seenNames, seenIDs := make(map[string]bool), make(map[string]bool)
+	for _, it := range list {
+		switch {
+		case it.ID == "" && !seenNames[it.Name]:
+			seenNames[it.Name] = true
+			names = append(names, it.Name)
+		case it.ID != "" && !t.Opaque && !seenIDs[it.ID]:
+			seenIDs[it.ID] = true
+			clientIDs = append(clientIDs, it.ID)
 		}
-		query := fmt.Sprintf(sqlquere.SynceNewAuthData, strings.Join(placeholders, ","))
-		s.zlog.Debug().Str("queue", query).Msg("Text new data quere")
-		s.zlog.Debug().Any("Names", names).Msg("Check")
-		rows, err := tx.Query(gCtx, query, uId)
+	}
+	ids := make(map[string]string)
+	if len(names) > 0 {
+		found, err := q.FindItemIDs(ctx, t, uID, names)
 		if err != nil {
-			s.zlog.Error().Err(err).Msg("sql query error")
-			return err
-		}
-		defer rows.Close()
-		for rows.Next() {
-			var login models.SyncLoginModel
-			var updated time.Time
-			err := rows.Scan(&login.Name, &login.Login, &login.Password, &login.UserID, &login.Deleted, &updated)
-			if err != nil {
-				if !errors.Is(err, pgx.ErrNoRows) {
-					s.zlog.Error().Err(err).Msg("Scan new auth data error")
-					return err
-				}
-			} else {
-				login.Updated = updated.Format(time.RFC3339)
-				login.Name = strings.TrimSpace(login.Name)
-				login.Login = strings.TrimSpace(login.Login)
-				login.Password = strings.TrimSpace(login.Password)
-				logins = append(logins, login)
-			}
+			return nil, nil, err
 		}
-		s.zlog.Debug().Msg("Sync auth end")
-		return tx.Commit(gCtx)
-	})
-
-	// Бинари.
-	group.Go(func() error {
-		s.zlog.Debug().Msg("Run bin sync")
-		tx, err := s.db.Begin(gCtx)
+		ids = found
+	}
+	existing := make(map[string]bool)
+	if len(clientIDs) > 0 {
+		found, err := q.ExistingItemIDs(ctx, t, uID, clientIDs)
 		if err != nil {
-			s.zlog.Debug().Err(err).Msg("Begin tx error")
-			return err
+			return nil, nil, err
 		}
-		defer tx.Rollback(gCtx)
+		existing = found
+	}
 
-		var names []string
-		for _, data := range model.Bins {
-			names = append(names, data.Name)
-			cTag, err := tx.Exec(gCtx, sqlquere.SyncUpdateBinTable,
-				data.Name, data.Data, data.UserID, data.Deleted, data.Updated, data.Name,
-				data.Updated, data.UserID, data.Name, data.Data, data.UserID, data.Deleted, data.Updated,
-				data.Name, data.UserID)
-			if err != nil {
-				s.zlog.Error().Err(err).Msg("Update bin table error")
-				return err
+	res := slices.Clone(list)
+	assigned := make(map[string]bool)
+	for i := range res {
+		if id, ok := replaced[res[i].FolderID]; ok {
+			res[i].FolderID = id
+		}
+		switch {
+		case res[i].ID == "":
+			id, ok := ids[res[i].Name]
+			if !ok {
+				id = uuid.NewString()
+				ids[res[i].Name] = id
 			}
-			s.zlog.Debug().Any("commandTag", cTag).Msg("update bin result")
-			row := tx.QueryRow(gCtx, sqlquere.SynceBinTableActual, data.Name, data.Updated)
-			var bin models.SyncBinaryDataModel
-			var updated time.Time
-			err = row.Scan(&bin.Name, &bin.Data, &bin.UserID, &bin.Deleted, &updated)
-			if err != nil {
-				if !errors.Is(err, pgx.ErrNoRows) {
-					s.zlog.Error().Err(err).Msg("Scan actual bin data error")
-					return err
-				}
-			} else {
-				bin.Updated = updated.Format(time.RFC3339)
-				bin.Name = strings.TrimSpace(bin.Name)
-				sBins = append(sBins, bin)
+			res[i].ID = id
+		case !t.Opaque && !existing[res[i].ID]:
+			id, ok := replaced[res[i].ID]
+			if !ok {
+				id = uuid.NewString()
+				replaced[res[i].ID] = id
 			}
+			res[i].ID = id
+		default:
+			continue
 		}
-		placeholders := make([]string, len(names))
-		for i, name := range names {
-			placeholders[i] = fmt.Sprintf("'%s'", name)
-		}
-		query := fmt.Sprintf(sqlquere.SynceNewBinData, strings.Join(placeholders, ","))
+		assigned[res[i].ID] = true
+	}
+	return res, assigned, nil
+}
 
-		s.zlog.Debug().Str("queue", query).Msg("Text new data quere")
-		s.zlog.Debug().Any("Names", names).Msg("Check")
-		rows, err := tx.Query(gCtx, query, uId)
-		if err != nil {
-			s.zlog.Error().Err(err).Msg("sql query error")
-			return err
+// latestVersions - по одной записи на id: самая новая версия, при равном
+// времени изменения - первая в списке.
+func latestVersions(list []items.Item) []items.Item {
+	pos := make(map[string]int, len(list))
+	res := make([]items.Item, 0, len(list))
+	for _, it := range list {
+		i, ok := pos[it.ID]
+		if !ok {
+			pos[it.ID] = len(res)
+			res = append(res, it)
+			continue
 		}
-		defer rows.Close()
-		for rows.Next() {
-			var bin models.SyncBinaryDataModel
-			var updated time.Time
-			err := rows.Scan(&bin.Name, &bin.Data, &bin.UserID, &bin.Deleted, &updated)
-			if err != nil {
-				if !errors.Is(err, pgx.ErrNoRows) {
-					s.zlog.Error().Err(err).Msg("Scan new bin data error")
-					return err
-				}
-			} else {
-				bin.Updated = updated.Format(time.RFC3339)
-				bin.Name = strings.TrimSpace(bin.Name)
-				sBins = append(sBins, bin)
-			}
+		if it.Updated.After(res[i].Updated) {
+			res[i] = it
 		}
+	}
+	return res
+}
 
-		s.zlog.Debug().Msg("Sync bin end")
-		return tx.Commit(gCtx)
-	})
-
-	// Карты
-	group.Go(func() error {
-		s.zlog.Debug().Msg("Run card sync")
-		tx, err := s.db.Begin(gCtx)
-		if err != nil {
-			s.zlog.Debug().Err(err).Msg("Begin tx error")
-			return err
+// checkFolders - все папки, на которые ссылаются записи, принадлежат пользователю.
+func checkFolders(ctx context.Context, q *queries.Queries, list []items.Item, uID int) error {
+	var folderIDs []string
+	for _, it := range list {
+		if it.FolderID != "" && !slices.Contains(folderIDs, it.FolderID) {
+			folderIDs = append(folderIDs, it.FolderID)
 		}
-		defer tx.Rollback(gCtx)
+	}
+	if len(folderIDs) == 0 {
+		return nil
+	}
+	missing, err := q.MissingFolders(ctx, uID, folderIDs)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrFolderNotExist, strings.Join(missing, ", "))
+	}
+	return nil
+}
 
-		var names []string
-		for _, data := range model.Cards {
-			names = append(names, data.Name)
-			cTag, err := tx.Exec(gCtx, sqlquere.SyncUpdateCardTable,
-				data.Name, data.Number, data.Date, data.CVVCode, data.UserID, data.Deleted, data.Updated, data.Name, data.Updated, data.UserID,
-				data.Name, data.Number, data.Date, data.CVVCode, data.UserID, data.Deleted, data.Updated, data.Name, data.UserID)
-			if err != nil {
-				s.zlog.Error().Err(err).Msg("Update Card table error")
-				return err
-			}
-			s.zlog.Debug().Any("commandTag", cTag).Msg("update Card result")
-			row := tx.QueryRow(gCtx, sqlquere.SynceCardTableActual, data.Name, data.Updated)
-			var card models.SyncCardModel
-			var updated time.Time
-			err = row.Scan(&card.Name, &card.Number, &card.Date, &card.CVVCode, &card.UserID, &card.Deleted, &updated)
-			if err != nil {
-				if !errors.Is(err, pgx.ErrNoRows) {
-					s.zlog.Error().Err(err).Msg("Scan actual card data error")
-					return err
-				}
-			} else {
-				card.Updated = updated.Format(time.RFC3339)
-				card.Name = strings.TrimSpace(card.Name)
-				card.Number = strings.TrimSpace(card.Number)
-				card.Date = strings.TrimSpace(card.Date)
-				sCards = append(sCards, card)
-			}
-		}
+// ListItems - страница метаданных записей. При withSecrets возвращаются также
+// записи целиком по id.
+func (s *KeepStorage) ListItems(ctx context.Context, uID int, f items.Filter, after *items.Cursor, limit int,
+	withSecrets bool) ([]items.Meta, map[string]items.Item, error) {
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
 
-		placeholders := make([]string, len(names))
-		for i, name := range names {
-			placeholders[i] = fmt.Sprintf("'%s'", name)
+	metas, err := q.ListItemMeta(ctx, uID, f, after, limit)
+	if err != nil {
+		s.zlog.Error().Err(err).Msg("List items error")
+		return nil, nil, err
+	}
+	if !withSecrets {
+		return metas, nil, tx.Commit(ctx)
+	}
+
+	byType := make(map[string][]string)
+	for _, m := range metas {
+		byType[m.Type] = append(byType[m.Type], m.ID)
+	}
+	full := make(map[string]items.Item, len(metas))
+	for name, ids := range byType {
+		t, ok := items.Lookup(name)
+		if !ok {
+			continue
 		}
-		query := fmt.Sprintf(sqlquere.SynceNewCardData, strings.Join(placeholders, ","))
-		s.zlog.Debug().Str("queue", query).Msg("Text new data quere")
-		s.zlog.Debug().Any("Names", names).Msg("Check")
-		rows, err := tx.Query(gCtx, query, uId)
+		list, err := q.GetItemsByIDs(ctx, t, uID, ids)
 		if err != nil {
-			s.zlog.Error().Err(err).Msg("sql query error")
-			return err
+			s.zlog.Error().Err(err).Msg("Get items by ids error")
+			return nil, nil, err
 		}
-		defer rows.Close()
-		for rows.Next() {
-			var card models.SyncCardModel
-			var updated time.Time
-			err := rows.Scan(&card.Name, &card.Number, &card.Date, &card.CVVCode, &card.UserID, &card.Deleted, &updated)
-			if err != nil {
-				if !errors.Is(err, pgx.ErrNoRows) {
-					s.zlog.Error().Err(err).Msg("Scan new card data error")
-					return err
-				}
-			} else {
-				card.Updated = updated.Format(time.RFC3339)
-				card.Name = strings.TrimSpace(card.Name)
-				card.Number = strings.TrimSpace(card.Number)
-				card.Date = strings.TrimSpace(card.Date)
-				sCards = append(sCards, card)
-			}
+		for _, it := range list {
+			full[it.ID] = it
 		}
+	}
+	return metas, full, tx.Commit(ctx)
+}
 
-		s.zlog.Debug().Msg("Sync card end")
-		return tx.Commit(gCtx)
-	})
+// GetItem - запись типа t по id.
+func (s *KeepStorage) GetItem(ctx context.Context, t *items.Type, uID int, id string) (items.Item, error) {
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return items.Item{}, err
+	}
+	defer tx.Rollback(ctx)
 
-	err := group.Wait()
+	it, err := q.GetItem(ctx, t, uID, id)
 	if err != nil {
-		s.zlog.Error().Err(err).Msg("Error group error")
-		return models.SyncModel{}, err
-	}
-	s.zlog.Debug().Msg("Sync done")
-	s.zlog.Debug().Int("actual text", len(sTexts)).
-		Int("actual bin", len(sBins)).
-		Int("actual auth", len(logins)).
-		Int("actual cards", len(sCards)).Any("text", sTexts).Msg("Actual data len")
-	return models.SyncModel{
-		Texts: sTexts,
-		Auth:  logins,
-		Cards: sCards,
-		Bins:  sBins,
-	}, nil
+		if errors.Is(err, pgx.ErrNoRows) {
+			return items.Item{}, ErrItemNotExist
+		}
+		return items.Item{}, err
+	}
+	return it, tx.Commit(ctx)
 }
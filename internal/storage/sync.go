@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	errText "github.com/Dorrrke/GophKeeper-server/internal/domain/errors"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+	"github.com/Dorrrke/GophKeeper-server/internal/storage/queries"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrIdempotencyKeyReused = errors.New(errText.IdempotencyKeyReusedError)
+	ErrSyncInProgress       = errors.New(errText.SyncInProgressError)
+	ErrSyncCursorNotExist   = errors.New(errText.SyncCursorNotExistError)
+)
+
+// SyncPaging - ограничения страницы ответа синхронизации: число записей,
+// их суммарный размер в байтах и время хранения остальных страниц.
+// Страница содержит хотя бы одну запись, даже если та больше Bytes.
+type SyncPaging struct {
+	Size  int
+	Bytes int
+	TTL   time.Duration
+}
+
+// syncEntry - запись ответа синхронизации вместе с ее типом.
+type syncEntry struct {
+	t  *items.Type
+	it items.Item
+}
+
+// BeginSyncRequest - начало синхронизации с ключом идемпотентности key.
+// Возвращает сохраненный ответ, если запрос с этим ключом уже выполнен, или
+// nil, если синхронизацию нужно выполнить. Ключ хранится ttl; отметка
+// о незавершенной синхронизации старше lease считается брошенной
+// (например, после перезапуска сервера) и передается новому запросу.
+func (s *KeepStorage) BeginSyncRequest(ctx context.Context, uID int, key string, hash []byte, ttl, lease time.Duration) ([]byte, error) {
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := q.DeleteExpiredSyncRequests(ctx, uID); err != nil {
+		return nil, err
+	}
+	created, err := q.CreateSyncRequest(ctx, uID, key, hash, time.Now().Add(ttl))
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		stored, response, started, err := q.GetSyncRequest(ctx, uID, key)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case !bytes.Equal(stored, hash):
+			return nil, ErrIdempotencyKeyReused
+		case response != nil:
+			return response, nil
+		case time.Since(started) < lease:
+			return nil, ErrSyncInProgress
+		}
+		if err := q.RestartSyncRequest(ctx, uID, key); err != nil {
+			return nil, err
+		}
+	}
+	return nil, tx.Commit(ctx)
+}
+
+// AbortSyncRequest - освобождение ключа после неудачной синхронизации,
+// чтобы повтор запроса выполнил ее заново.
+func (s *KeepStorage) AbortSyncRequest(ctx context.Context, uID int, key string) error {
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := q.DeleteSyncRequest(ctx, uID, key); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// SyncPage - страница ответа синхронизации из курсора cursorID, начиная
+// с позиции from. Возвращает позицию следующей страницы или -1, если
+// страниц больше нет. Записи, удаленные после синхронизации, пропускаются.
+func (s *KeepStorage) SyncPage(ctx context.Context, uID int, cursorID string, from int, paging SyncPaging) (items.Set, int, error) {
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	ok, err := q.SyncCursorExists(ctx, cursorID, uID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !ok {
+		return nil, 0, ErrSyncCursorNotExist
+	}
+	// Лишняя запись показывает, есть ли следующая страница.
+	refs, err := q.ListSyncCursorItems(ctx, cursorID, uID, from, paging.Size+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	next := -1
+	if len(refs) > paging.Size {
+		next = refs[paging.Size].Pos
+		refs = refs[:paging.Size]
+	}
+
+	byType := make(map[string][]string)
+	for _, ref := range refs {
+		if !ref.Deleted {
+			byType[ref.Type] = append(byType[ref.Type], ref.ID)
+		}
+	}
+	stored := make(map[string]items.Item)
+	for name, ids := range byType {
+		t, ok := items.Lookup(name)
+		if !ok {
+			continue
+		}
+		list, err := q.GetItemsByIDs(ctx, t, uID, ids)
+		if err != nil {
+			s.zlog.Error().Err(err).Msg("Get items by ids error")
+			return nil, 0, err
+		}
+		for _, it := range list {
+			stored[it.ID] = it
+		}
+	}
+
+	entries := make([]syncEntry, 0, len(refs))
+	positions := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		t, ok := items.Lookup(ref.Type)
+		if !ok {
+			continue
+		}
+		it, ok := stored[ref.ID]
+		if ref.Deleted {
+			it = items.Item{ID: ref.ID, Type: t.Name, UserID: uID, Name: ref.Name, Deleted: true, Updated: ref.Updated}
+		} else if !ok {
+			continue
+		}
+		entries = append(entries, syncEntry{t: t, it: it})
+		positions = append(positions, ref.Pos)
+	}
+	n := pageLen(entries, paging)
+	if n < len(entries) {
+		next = positions[n]
+	}
+	return entriesToSet(entries[:n]), next, tx.Commit(ctx)
+}
+
+// saveSyncCursor - сохранение записей ответа, не вошедших в первую страницу.
+func saveSyncCursor(ctx context.Context, q *queries.Queries, uID int, rest []syncEntry, ttl time.Duration) (string, error) {
+	if err := q.DeleteExpiredSyncCursors(ctx, uID); err != nil {
+		return "", err
+	}
+	id, err := q.CreateSyncCursor(ctx, uID, time.Now().Add(ttl))
+	if err != nil {
+		return "", err
+	}
+	list := make([]queries.CursorItem, 0, len(rest))
+	for _, e := range rest {
+		list = append(list, queries.CursorItem{
+			Type: e.t.Name, ID: e.it.ID, Name: e.it.Name, Deleted: e.it.Deleted, Updated: e.it.Updated,
+		})
+	}
+	return id, q.AddSyncCursorItems(ctx, id, uID, list)
+}
+
+// pageLen - число первых записей, помещающихся на страницу.
+func pageLen(entries []syncEntry, paging SyncPaging) int {
+	n, size := 0, 0
+	for n < len(entries) && n < paging.Size {
+		sz := entries[n].t.Size(entries[n].it)
+		if n > 0 && size+sz > paging.Bytes {
+			break
+		}
+		size += sz
+		n++
+	}
+	return n
+}
+
+func entriesToSet(entries []syncEntry) items.Set {
+	set := make(items.Set)
+	for _, e := range entries {
+		set[e.t.Name] = append(set[e.t.Name], e.it)
+	}
+	return set
+}
+
+// sortedTypes - зарегистрированные типы по возрастанию Order.
+func sortedTypes() []*items.Type {
+	types := slices.Clone(items.Types())
+	slices.SortStableFunc(types, func(a, b *items.Type) int { return a.Order - b.Order })
+	return types
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/models"
+	"github.com/Dorrrke/GophKeeper-server/internal/storage/queries"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// benchSyncItems - число записей в одном запросе синхронизации.
+const benchSyncItems = 10000
+
+// benchStorage - хранилище на базе из DATABASE_URL и пользователь для
+// тестовых записей. База должна быть мигрирована (migrator up).
+func benchStorage(b *testing.B) (*KeepStorage, int) {
+	b.Helper()
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		b.Skip("DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(pool.Close)
+	zlog := zerolog.Nop()
+	s := New(pool, &zlog)
+
+	login := "bench-" + uuid.NewString()
+	uID, err := s.SaveUser(ctx, models.UserModel{Login: login, Hash: "bench"}, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() {
+		if err := s.DeleteUser(context.Background(), int(uID)); err != nil {
+			b.Error(err)
+		}
+	})
+	return s, int(uID)
+}
+
+// benchItems - n текстовых записей пользователя с id, назначенными клиенту.
+func benchItems(uID, n int, updated time.Time) []items.Item {
+	list := make([]items.Item, n)
+	for i := range list {
+		list[i] = items.Item{
+			ID:      uuid.NewString(),
+			Type:    items.Text.Name,
+			UserID:  uID,
+			Name:    fmt.Sprintf("text %d", i),
+			Values:  map[string]any{"data": "bench data"},
+			Updated: updated,
+		}
+	}
+	return list
+}
+
+// withVersion - копия записей с другим временем изменения.
+func withVersion(list []items.Item, updated time.Time) []items.Item {
+	res := make([]items.Item, len(list))
+	for i, it := range list {
+		it.Updated = updated
+		res[i] = it
+	}
+	return res
+}
+
+// upsertEach - запись по одной командой UpsertItem, как до COPY.
+func upsertEach(ctx context.Context, q *queries.Queries, list []items.Item) error {
+	for _, it := range list {
+		if _, err := q.UpsertItem(ctx, items.Text, it); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stageAndMerge - загрузка одним COPY и слияние одним запросом, как в syncType.
+func stageAndMerge(ctx context.Context, q *queries.Queries, list []items.Item) error {
+	if err := q.StageItems(ctx, items.Text, list, nil); err != nil {
+		return err
+	}
+	_, err := q.MergeStagedItems(ctx, items.Text)
+	return err
+}
+
+// BenchmarkSyncItems - сравнение записи benchSyncItems записей по одной
+// и через COPY со слиянием. В сценарии insert все записи новые, в update
+// на сервере лежат их более старые версии. Каждая итерация выполняется
+// в транзакции, которая откатывается, чтобы итерации были одинаковыми.
+func BenchmarkSyncItems(b *testing.B) {
+	s, uID := benchStorage(b)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	stored := benchItems(uID, benchSyncItems, now.Add(-time.Hour))
+	tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := stageAndMerge(ctx, q, stored); err != nil {
+		tx.Rollback(ctx)
+		b.Fatal(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		b.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name string
+		list []items.Item
+	}{
+		{"insert", benchItems(uID, benchSyncItems, now)},
+		{"update", withVersion(stored, now)},
+	}
+	methods := []struct {
+		name string
+		run  func(context.Context, *queries.Queries, []items.Item) error
+	}{
+		{"upsert", upsertEach},
+		{"copy_merge", stageAndMerge},
+	}
+	for _, sc := range scenarios {
+		for _, m := range methods {
+			b.Run(sc.name+"/"+m.name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					tx, q, err := s.beginUserTx(ctx, uID, pgx.TxOptions{})
+					if err != nil {
+						b.Fatal(err)
+					}
+					err = m.run(ctx, q, sc.list)
+					tx.Rollback(ctx)
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(b.Elapsed().Microseconds())/float64(b.N*len(sc.list)), "us/item")
+			})
+		}
+	}
+}
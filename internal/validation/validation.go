@@ -0,0 +1,136 @@
+// Package validation - проверка запросов клиента до обращения к сервису.
+//
+// Ограничения выводятся из схемы базы данных, чтобы недопустимое значение
+// отклонялось с понятной ошибкой по полю, а не падало на записи в таблицу.
+// Проверка собирает все ошибки запроса, а не останавливается на первой.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Dorrrke/GophKeeper-server/internal/domain/items"
+	gophkeeperv1 "github.com/Dorrrke/goph-keeper-proto/gen/go/gophkeeper"
+)
+
+const (
+	// MaxLoginLen - длина колонки login таблицы users.
+	MaxLoginLen = 100
+	// MaxPasswordLen - предел длины пароля. Пароль не хранится, предел
+	// ограничивает объем работы при вычислении верификатора.
+	MaxPasswordLen = 1024
+)
+
+// Error - ошибки проверки запроса по полям. Field каждой ошибки - полный
+// путь к полю в запросе, например "cards[2].number".
+type Error struct {
+	Violations []items.FieldViolation
+}
+
+func (e *Error) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.Field+": "+v.Description)
+	}
+	return "invalid request: " + strings.Join(parts, "; ")
+}
+
+func (e *Error) add(field, format string, args ...any) {
+	e.Violations = append(e.Violations, items.FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+}
+
+// err - e, если есть ошибки, иначе nil.
+func (e *Error) err() error {
+	if len(e.Violations) == 0 {
+		return nil
+	}
+	return e
+}
+
+// SignUp - проверка запроса регистрации по паролю.
+func SignUp(req *gophkeeperv1.SignUpRequest) error {
+	var e Error
+	checkLogin(&e, "login", req.GetLogin())
+	checkPassword(&e, "password", req.GetPassword())
+	return e.err()
+}
+
+// SignIn - проверка запроса входа по паролю.
+func SignIn(req *gophkeeperv1.SingInRequest) error {
+	var e Error
+	checkLogin(&e, "login", req.GetLogin())
+	checkPassword(&e, "password", req.GetPassword())
+	return e.err()
+}
+
+// SrpRegister - проверка логина при регистрации по SRP; соль
+// и верификатор проверяет сервис.
+func SrpRegister(req *gophkeeperv1.SrpRegisterRequest) error {
+	var e Error
+	checkLogin(&e, "login", req.GetLogin())
+	return e.err()
+}
+
+// SrpStart - проверка логина при входе по SRP.
+func SrpStart(req *gophkeeperv1.SrpStartRequest) error {
+	var e Error
+	checkLogin(&e, "login", req.GetLogin())
+	return e.err()
+}
+
+// ChangeLogin - проверка нового логина.
+func ChangeLogin(req *gophkeeperv1.ChangeLoginRequest) error {
+	var e Error
+	checkLogin(&e, "new_login", req.GetNewLogin())
+	return e.err()
+}
+
+// SyncDB - разбор и проверка записей запроса синхронизации всех типов.
+func SyncDB(req *gophkeeperv1.SyncDBRequest, uID int) (items.Set, error) {
+	set, err := items.FromProto(req, uID)
+	if err == nil {
+		return set, nil
+	}
+	var e Error
+	for _, itemErr := range unjoin(err) {
+		var vErr *items.ValidationError
+		if !errors.As(itemErr, &vErr) {
+			return nil, err
+		}
+		for _, v := range vErr.Violations {
+			e.add(vErr.FieldPath(v), "%s", v.Description)
+		}
+	}
+	return nil, e.err()
+}
+
+// unjoin - ошибки, объединенные errors.Join, или сама err.
+func unjoin(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+func checkLogin(e *Error, field, login string) {
+	switch {
+	case strings.TrimSpace(login) == "":
+		e.add(field, "is required")
+	case utf8.RuneCountInString(login) > MaxLoginLen:
+		e.add(field, "must be at most %d characters", MaxLoginLen)
+	case strings.IndexFunc(login, unicode.IsControl) >= 0:
+		e.add(field, "must not contain control characters")
+	}
+}
+
+func checkPassword(e *Error, field, pass string) {
+	switch {
+	case pass == "":
+		e.add(field, "is required")
+	case utf8.RuneCountInString(pass) > MaxPasswordLen:
+		e.add(field, "must be at most %d characters", MaxPasswordLen)
+	}
+}
@@ -0,0 +1,126 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	gophkeeperv1 "github.com/Dorrrke/goph-keeper-proto/gen/go/gophkeeper"
+)
+
+// fields - поля ошибок проверки или nil, если ошибки нет.
+func fields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var vErr *Error
+	if !errors.As(err, &vErr) {
+		t.Fatalf("error %v is not *Error", err)
+	}
+	res := make([]string, 0, len(vErr.Violations))
+	for _, v := range vErr.Violations {
+		res = append(res, v.Field)
+	}
+	return res
+}
+
+func TestSignIn(t *testing.T) {
+	tests := []struct {
+		name   string
+		login  string
+		pass   string
+		fields []string
+	}{
+		{"valid", "user", "secret", nil},
+		{"login at limit", strings.Repeat("л", MaxLoginLen), "secret", nil},
+		{"empty login", "", "secret", []string{"login"}},
+		{"blank login", "  \t", "secret", []string{"login"}},
+		{"long login", strings.Repeat("a", MaxLoginLen+1), "secret", []string{"login"}},
+		{"control character", "us\x00er", "secret", []string{"login"}},
+		{"empty password", "user", "", []string{"password"}},
+		{"password at limit", "user", strings.Repeat("p", MaxPasswordLen), nil},
+		{"long password", "user", strings.Repeat("p", MaxPasswordLen+1), []string{"password"}},
+		{"both invalid", "", "", []string{"login", "password"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SignIn(&gophkeeperv1.SingInRequest{Login: tt.login, Password: tt.pass})
+			if got := fields(t, err); strings.Join(got, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("SignIn fields = %v, want %v", got, tt.fields)
+			}
+			err = SignUp(&gophkeeperv1.SignUpRequest{Login: tt.login, Password: tt.pass})
+			if got := fields(t, err); strings.Join(got, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("SignUp fields = %v, want %v", got, tt.fields)
+			}
+		})
+	}
+}
+
+func TestLoginOnly(t *testing.T) {
+	tests := []struct {
+		name  string
+		check func(login string) error
+		field string
+	}{
+		{"SrpRegister", func(l string) error { return SrpRegister(&gophkeeperv1.SrpRegisterRequest{Login: l}) }, "login"},
+		{"SrpStart", func(l string) error { return SrpStart(&gophkeeperv1.SrpStartRequest{Login: l}) }, "login"},
+		{"ChangeLogin", func(l string) error { return ChangeLogin(&gophkeeperv1.ChangeLoginRequest{NewLogin: l}) }, "new_login"},
+	}
+	for _, tt := range tests {
+		if err := tt.check("user"); err != nil {
+			t.Errorf("%s: valid login rejected: %v", tt.name, err)
+		}
+		for _, login := range []string{"", strings.Repeat("a", MaxLoginLen+1), "a\nb"} {
+			got := fields(t, tt.check(login))
+			if len(got) != 1 || got[0] != tt.field {
+				t.Errorf("%s(%q): fields = %v, want [%s]", tt.name, login, got, tt.field)
+			}
+		}
+	}
+}
+
+func TestSyncDB(t *testing.T) {
+	const updated = "2024-01-02T03:04:05Z"
+	card := func(change func(*gophkeeperv1.SyncCard)) *gophkeeperv1.SyncCard {
+		c := &gophkeeperv1.SyncCard{Name: "card", Number: "4111111111111111", Date: "12/30", Cvv: "123", Updated: updated}
+		if change != nil {
+			change(c)
+		}
+		return c
+	}
+	tests := []struct {
+		name   string
+		req    *gophkeeperv1.SyncDBRequest
+		fields []string
+	}{
+		{"empty", &gophkeeperv1.SyncDBRequest{}, nil},
+		{"valid card", &gophkeeperv1.SyncDBRequest{Cards: []*gophkeeperv1.SyncCard{card(nil)}}, nil},
+		{"paths of several items", &gophkeeperv1.SyncDBRequest{Cards: []*gophkeeperv1.SyncCard{
+			card(nil),
+			card(func(c *gophkeeperv1.SyncCard) { c.Number = "4111111111111112" }),
+			card(func(c *gophkeeperv1.SyncCard) { c.Date = "13/30"; c.Cvv = "1" }),
+		}}, []string{"cards[1].number", "cards[2].date", "cards[2].cvv"}},
+		{"deleted card over column length", &gophkeeperv1.SyncDBRequest{Cards: []*gophkeeperv1.SyncCard{
+			card(func(c *gophkeeperv1.SyncCard) { c.Deleted = true; c.Pin = strings.Repeat("1", 13) }),
+		}}, []string{"cards[0].pin"}},
+		{"text name too long", &gophkeeperv1.SyncDBRequest{Texts: []*gophkeeperv1.SyncText{
+			{Name: strings.Repeat("t", 101), Data: "data", Updated: updated},
+		}}, []string{"texts[0].name"}},
+		{"bad time", &gophkeeperv1.SyncDBRequest{Texts: []*gophkeeperv1.SyncText{
+			{Name: "text", Data: "data", Updated: "yesterday"},
+		}}, []string{"texts[0].updated"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := SyncDB(tt.req, 1)
+			got := fields(t, err)
+			if strings.Join(got, ",") != strings.Join(tt.fields, ",") {
+				t.Fatalf("fields = %v, want %v", got, tt.fields)
+			}
+			if err == nil && set == nil {
+				t.Error("nil set without error")
+			}
+		})
+	}
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS key_bundles;
+DROP TABLE IF EXISTS encrypted_items;
+DROP FUNCTION IF EXISTS encrypted_items_bump_revision();
+DROP SEQUENCE IF EXISTS encrypted_items_revision_seq;
@@ -0,0 +1,59 @@
+-- Записи, зашифрованные клиентом, и ключи пользователя в обернутом виде.
+-- Сервер не видит содержимого: открыто хранятся только id, тип
+-- содержимого, ревизия и признак удаления.
+
+CREATE SEQUENCE IF NOT EXISTS encrypted_items_revision_seq;
+
+CREATE TABLE IF NOT EXISTS encrypted_items (
+    id uuid PRIMARY KEY,
+    -- Непрозрачная метка клиента, не уникальна.
+    name varchar(100) NOT NULL DEFAULT '',
+    kind varchar(32) NOT NULL,
+    ciphertext bytea NOT NULL,
+    revision bigint NOT NULL DEFAULT nextval('encrypted_items_revision_seq'),
+    uId integer NOT NULL REFERENCES users (uId) ON DELETE CASCADE,
+    deleted boolean NOT NULL,
+    last_update timestamp with time zone NOT NULL
+);
+CREATE INDEX encrypted_items_uid_idx ON encrypted_items (uId);
+
+-- Ревизия назначается при каждом изменении записи и не задается клиентом.
+CREATE OR REPLACE FUNCTION encrypted_items_bump_revision() RETURNS trigger AS $$
+BEGIN
+    NEW.revision := nextval('encrypted_items_revision_seq');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER encrypted_items_revision
+    BEFORE INSERT OR UPDATE ON encrypted_items
+    FOR EACH ROW EXECUTE FUNCTION encrypted_items_bump_revision();
+
+ALTER TABLE encrypted_items ENABLE ROW LEVEL SECURITY;
+ALTER TABLE encrypted_items FORCE ROW LEVEL SECURITY;
+CREATE POLICY encrypted_items_tenant ON encrypted_items
+    USING (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on')
+    WITH CHECK (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on');
+
+-- Ключ хранилища пользователя, обернутый ключом клиента (например,
+-- производным от пароля или ключом восстановления). name различает
+-- обертки одного пользователя, params - параметры обертки для клиента.
+CREATE TABLE IF NOT EXISTS key_bundles (
+    uId integer NOT NULL REFERENCES users (uId) ON DELETE CASCADE,
+    name varchar(50) NOT NULL,
+    algorithm varchar(50) NOT NULL,
+    wrapped_key bytea NOT NULL,
+    params bytea NOT NULL DEFAULT '',
+    last_update timestamp with time zone NOT NULL,
+    PRIMARY KEY (uId, name)
+);
+
+ALTER TABLE key_bundles ENABLE ROW LEVEL SECURITY;
+ALTER TABLE key_bundles FORCE ROW LEVEL SECURITY;
+CREATE POLICY key_bundles_tenant ON key_bundles
+    USING (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on')
+    WITH CHECK (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on');
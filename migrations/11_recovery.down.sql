@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS account_events;
+DROP TABLE IF EXISTS emergency_contacts;
+DROP TABLE IF EXISTS recovery_codes;
@@ -0,0 +1,67 @@
+-- Восстановление доступа: одноразовые коды восстановления, доверенные
+-- контакты для экстренного доступа и журнал событий учетной записи.
+
+-- Коды хранятся только в виде SHA-256; used_at - время использования.
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    uId integer NOT NULL REFERENCES users (uId) ON DELETE CASCADE,
+    code_hash bytea NOT NULL,
+    used_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (uId, code_hash)
+);
+
+ALTER TABLE recovery_codes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE recovery_codes FORCE ROW LEVEL SECURITY;
+CREATE POLICY recovery_codes_tenant ON recovery_codes
+    USING (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on')
+    WITH CHECK (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on');
+
+-- Доверенный контакт владельца. Контакт может запросить доступ
+-- (requested_at); доступ предоставляется, если владелец не отклонил
+-- запрос за wait_seconds. Строка видна и владельцу, и контакту.
+CREATE TABLE IF NOT EXISTS emergency_contacts (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id integer NOT NULL REFERENCES users (uId) ON DELETE CASCADE,
+    contact_id integer NOT NULL REFERENCES users (uId) ON DELETE CASCADE,
+    wait_seconds bigint NOT NULL CHECK (wait_seconds >= 0),
+    requested_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    UNIQUE (owner_id, contact_id),
+    CHECK (owner_id <> contact_id)
+);
+CREATE INDEX emergency_contacts_contact_idx ON emergency_contacts (contact_id);
+
+ALTER TABLE emergency_contacts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE emergency_contacts FORCE ROW LEVEL SECURITY;
+CREATE POLICY emergency_contacts_tenant ON emergency_contacts
+    USING (owner_id = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR contact_id = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on')
+    WITH CHECK (owner_id = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR contact_id = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on');
+
+-- Журнал событий учетной записи uId. actor_id - пользователь, выполнивший
+-- действие (например, контакт, запросивший доступ). Записи только
+-- добавляются: политик UPDATE и DELETE нет.
+CREATE TABLE IF NOT EXISTS account_events (
+    id bigserial PRIMARY KEY,
+    uId integer NOT NULL REFERENCES users (uId) ON DELETE CASCADE,
+    actor_id integer REFERENCES users (uId) ON DELETE SET NULL,
+    event varchar(50) NOT NULL,
+    details jsonb NOT NULL DEFAULT '{}',
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+CREATE INDEX account_events_uid_idx ON account_events (uId, id);
+
+ALTER TABLE account_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE account_events FORCE ROW LEVEL SECURITY;
+CREATE POLICY account_events_read ON account_events FOR SELECT
+    USING (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on');
+CREATE POLICY account_events_write ON account_events FOR INSERT
+    WITH CHECK (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR actor_id = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on');
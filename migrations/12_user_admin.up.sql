@@ -0,0 +1,5 @@
+-- Администрирование учетных записей: блокировка и время последнего входа.
+-- locked_at - время блокировки; NULL, если учетная запись не заблокирована.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS locked_at timestamp with time zone,
+    ADD COLUMN IF NOT EXISTS last_login_at timestamp with time zone;
@@ -0,0 +1,22 @@
+-- Ключи идемпотентности синхронизации: ответ на запрос SyncDB хранится
+-- до expires_at и возвращается повторно на запрос с тем же ключом.
+-- request_hash - SHA-256 запроса; response - NULL, пока синхронизация
+-- выполняется (started_at - время ее начала).
+CREATE TABLE IF NOT EXISTS sync_requests (
+    uId integer NOT NULL REFERENCES users (uId) ON DELETE CASCADE,
+    key text NOT NULL,
+    request_hash bytea NOT NULL,
+    response bytea,
+    started_at timestamp with time zone NOT NULL DEFAULT now(),
+    expires_at timestamp with time zone NOT NULL,
+    PRIMARY KEY (uId, key)
+);
+CREATE INDEX IF NOT EXISTS idx_sync_requests_expires ON sync_requests (expires_at);
+
+ALTER TABLE sync_requests ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sync_requests FORCE ROW LEVEL SECURITY;
+CREATE POLICY sync_requests_tenant ON sync_requests
+    USING (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on')
+    WITH CHECK (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on');
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS sync_cursor_items;
+DROP TABLE IF EXISTS sync_cursors;
@@ -0,0 +1,38 @@
+-- Постраничные ответы синхронизации: записи ответа, не вошедшие в первую
+-- страницу, в порядке выдачи. Клиент забирает их по токену страницы до
+-- expires_at. Для удаленных записей сохраняются имя и время удаления,
+-- так как сами строки удаляются в транзакции синхронизации.
+CREATE TABLE IF NOT EXISTS sync_cursors (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    uId integer NOT NULL REFERENCES users (uId) ON DELETE CASCADE,
+    expires_at timestamp with time zone NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_sync_cursors_uid ON sync_cursors (uId, expires_at);
+
+CREATE TABLE IF NOT EXISTS sync_cursor_items (
+    cursor_id uuid NOT NULL REFERENCES sync_cursors (id) ON DELETE CASCADE,
+    uId integer NOT NULL REFERENCES users (uId) ON DELETE CASCADE,
+    pos integer NOT NULL,
+    type text NOT NULL,
+    item_id uuid NOT NULL,
+    name text NOT NULL,
+    deleted boolean NOT NULL,
+    last_update timestamp with time zone NOT NULL,
+    PRIMARY KEY (cursor_id, pos)
+);
+
+ALTER TABLE sync_cursors ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sync_cursors FORCE ROW LEVEL SECURITY;
+CREATE POLICY sync_cursors_tenant ON sync_cursors
+    USING (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on')
+    WITH CHECK (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on');
+
+ALTER TABLE sync_cursor_items ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sync_cursor_items FORCE ROW LEVEL SECURITY;
+CREATE POLICY sync_cursor_items_tenant ON sync_cursor_items
+    USING (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on')
+    WITH CHECK (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on');
@@ -0,0 +1,2 @@
+DELETE FROM srp_sessions WHERE uId IS NULL;
+ALTER TABLE srp_sessions ALTER COLUMN uId SET NOT NULL;
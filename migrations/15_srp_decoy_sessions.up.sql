@@ -0,0 +1,4 @@
+-- Сессии входа по SRP без пользователя: первый шаг для несуществующего
+-- логина или учетной записи без верификатора сохраняет такую сессию, чтобы
+-- второй шаг завершался той же ошибкой, что и при неверном пароле.
+ALTER TABLE srp_sessions ALTER COLUMN uId DROP NOT NULL;
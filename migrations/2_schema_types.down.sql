@@ -0,0 +1,57 @@
+ALTER TABLE binares_data
+    DROP CONSTRAINT IF EXISTS binares_data_uid_name_key,
+    DROP CONSTRAINT IF EXISTS binares_data_uid_fkey;
+ALTER TABLE text_data
+    DROP CONSTRAINT IF EXISTS text_data_uid_name_key,
+    DROP CONSTRAINT IF EXISTS text_data_uid_fkey;
+ALTER TABLE logins
+    DROP CONSTRAINT IF EXISTS logins_uid_name_key,
+    DROP CONSTRAINT IF EXISTS logins_uid_fkey;
+ALTER TABLE cards
+    DROP CONSTRAINT IF EXISTS cards_uid_name_key,
+    DROP CONSTRAINT IF EXISTS cards_uid_fkey;
+
+ALTER TABLE binares_data
+    ALTER COLUMN name DROP NOT NULL,
+    ALTER COLUMN data DROP NOT NULL,
+    ALTER COLUMN uId DROP NOT NULL,
+    ALTER COLUMN deleted DROP NOT NULL,
+    ALTER COLUMN last_update DROP NOT NULL;
+
+-- Строки из карантина возвращаются до смены типов: они сохранены
+-- в представлении новых колонок.
+SET app.maintenance = 'on';
+INSERT INTO cards
+    SELECT (jsonb_populate_record(NULL::cards, data)).* FROM migration_quarantine WHERE source = 'cards';
+INSERT INTO logins
+    SELECT (jsonb_populate_record(NULL::logins, data)).* FROM migration_quarantine WHERE source = 'logins';
+INSERT INTO text_data
+    SELECT (jsonb_populate_record(NULL::text_data, data)).* FROM migration_quarantine WHERE source = 'text_data';
+INSERT INTO binares_data
+    SELECT (jsonb_populate_record(NULL::binares_data, data)).* FROM migration_quarantine WHERE source = 'binares_data';
+RESET app.maintenance;
+
+DROP TABLE IF EXISTS migration_quarantine;
+
+-- Значения длиннее старых character(n) будут обрезаны.
+ALTER TABLE binares_data
+    ALTER COLUMN name TYPE character(25) USING left(name, 25),
+    ALTER COLUMN data TYPE character(255) USING left(convert_from(data, 'UTF8'), 255);
+
+ALTER TABLE text_data
+    ALTER COLUMN name TYPE character(25) USING left(name, 25),
+    ALTER COLUMN data TYPE character(255) USING left(data, 255);
+
+ALTER TABLE logins
+    ALTER COLUMN name TYPE character(25) USING left(name, 25),
+    ALTER COLUMN login TYPE character(50) USING left(login, 50),
+    ALTER COLUMN password TYPE character(50) USING left(password, 50);
+
+ALTER TABLE cards
+    ALTER COLUMN name TYPE character(25) USING left(name, 25),
+    ALTER COLUMN number TYPE character(16),
+    ALTER COLUMN date TYPE character(5);
+
+ALTER TABLE users
+    ALTER COLUMN login TYPE character(50) USING left(login, 50),
+    ALTER COLUMN hash TYPE character(200) USING left(hash, 200);
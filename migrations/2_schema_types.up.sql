@@ -0,0 +1,124 @@
+-- Переход с character(n) на text/varchar/bytea, NOT NULL, внешние ключи
+-- и уникальность имени записи в пределах пользователя.
+-- Данные мигрируются на месте: хвостовые пробелы character(n) обрезаются.
+
+ALTER TABLE users
+    ALTER COLUMN login TYPE varchar(100) USING rtrim(login),
+    ALTER COLUMN hash TYPE text USING rtrim(hash);
+
+-- Строки, которые нарушают новые ограничения, не удаляются, а переносятся
+-- в migration_quarantine для разбора вручную; откат миграции возвращает их
+-- в исходные таблицы. Таблица доступна только служебным инструментам.
+CREATE TABLE IF NOT EXISTS migration_quarantine (
+    id bigserial PRIMARY KEY,
+    source text NOT NULL,
+    reason text NOT NULL,
+    data jsonb NOT NULL,
+    moved_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+ALTER TABLE cards
+    ALTER COLUMN name TYPE varchar(100) USING rtrim(name),
+    ALTER COLUMN number TYPE varchar(16) USING rtrim(number),
+    ALTER COLUMN date TYPE varchar(5) USING rtrim(date);
+
+ALTER TABLE logins
+    ALTER COLUMN name TYPE varchar(100) USING rtrim(name),
+    ALTER COLUMN login TYPE varchar(255) USING rtrim(login),
+    ALTER COLUMN password TYPE text USING rtrim(password);
+
+ALTER TABLE text_data
+    ALTER COLUMN name TYPE varchar(100) USING rtrim(name),
+    ALTER COLUMN data TYPE text USING rtrim(data);
+
+ALTER TABLE binares_data
+    ALTER COLUMN name TYPE varchar(100) USING rtrim(name),
+    ALTER COLUMN data TYPE bytea USING convert_to(rtrim(data), 'UTF8');
+
+-- Неполные строки binares_data не могут быть синхронизированы, переносим их
+-- в карантин и заполняем значения по умолчанию перед NOT NULL.
+WITH moved AS (DELETE FROM binares_data WHERE name IS NULL OR uId IS NULL RETURNING *)
+INSERT INTO migration_quarantine (source, reason, data)
+    SELECT 'binares_data', 'incomplete', to_jsonb(moved) FROM moved;
+UPDATE binares_data SET deleted = false WHERE deleted IS NULL;
+UPDATE binares_data SET last_update = now() WHERE last_update IS NULL;
+UPDATE binares_data SET data = '' WHERE data IS NULL;
+
+ALTER TABLE binares_data
+    ALTER COLUMN name SET NOT NULL,
+    ALTER COLUMN data SET NOT NULL,
+    ALTER COLUMN uId SET NOT NULL,
+    ALTER COLUMN deleted SET NOT NULL,
+    ALTER COLUMN last_update SET NOT NULL;
+
+-- Записи несуществующих пользователей.
+WITH moved AS (DELETE FROM cards WHERE uId NOT IN (SELECT uId FROM users) RETURNING *)
+INSERT INTO migration_quarantine (source, reason, data)
+    SELECT 'cards', 'orphan', to_jsonb(moved) FROM moved;
+WITH moved AS (DELETE FROM logins WHERE uId NOT IN (SELECT uId FROM users) RETURNING *)
+INSERT INTO migration_quarantine (source, reason, data)
+    SELECT 'logins', 'orphan', to_jsonb(moved) FROM moved;
+WITH moved AS (DELETE FROM text_data WHERE uId NOT IN (SELECT uId FROM users) RETURNING *)
+INSERT INTO migration_quarantine (source, reason, data)
+    SELECT 'text_data', 'orphan', to_jsonb(moved) FROM moved;
+WITH moved AS (DELETE FROM binares_data WHERE uId NOT IN (SELECT uId FROM users) RETURNING *)
+INSERT INTO migration_quarantine (source, reason, data)
+    SELECT 'binares_data', 'orphan', to_jsonb(moved) FROM moved;
+
+-- Дубликаты имен: остается самая свежая запись.
+WITH moved AS (
+    DELETE FROM cards a USING cards b
+        WHERE a.uId = b.uId AND a.name = b.name AND (a.last_update, a.cId) < (b.last_update, b.cId)
+        RETURNING a.*)
+INSERT INTO migration_quarantine (source, reason, data)
+    SELECT 'cards', 'duplicate', to_jsonb(moved) FROM moved;
+WITH moved AS (
+    DELETE FROM logins a USING logins b
+        WHERE a.uId = b.uId AND a.name = b.name AND (a.last_update, a.lId) < (b.last_update, b.lId)
+        RETURNING a.*)
+INSERT INTO migration_quarantine (source, reason, data)
+    SELECT 'logins', 'duplicate', to_jsonb(moved) FROM moved;
+WITH moved AS (
+    DELETE FROM text_data a USING text_data b
+        WHERE a.uId = b.uId AND a.name = b.name AND (a.last_update, a.tId) < (b.last_update, b.tId)
+        RETURNING a.*)
+INSERT INTO migration_quarantine (source, reason, data)
+    SELECT 'text_data', 'duplicate', to_jsonb(moved) FROM moved;
+WITH moved AS (
+    DELETE FROM binares_data a USING binares_data b
+        WHERE a.uId = b.uId AND a.name = b.name AND (a.last_update, a.bId) < (b.last_update, b.bId)
+        RETURNING a.*)
+INSERT INTO migration_quarantine (source, reason, data)
+    SELECT 'binares_data', 'duplicate', to_jsonb(moved) FROM moved;
+
+DO $$
+DECLARE
+    moved bigint;
+BEGIN
+    SELECT count(*) INTO moved FROM migration_quarantine;
+    IF moved > 0 THEN
+        RAISE WARNING '% rows violating the new constraints were moved to migration_quarantine', moved;
+    END IF;
+END $$;
+
+ALTER TABLE migration_quarantine ENABLE ROW LEVEL SECURITY;
+ALTER TABLE migration_quarantine FORCE ROW LEVEL SECURITY;
+CREATE POLICY migration_quarantine_maintenance ON migration_quarantine
+    USING (current_setting('app.maintenance', true) = 'on')
+    WITH CHECK (current_setting('app.maintenance', true) = 'on');
+
+ALTER TABLE cards
+    ADD CONSTRAINT cards_uid_fkey FOREIGN KEY (uId) REFERENCES users (uId) ON DELETE CASCADE,
+    ADD CONSTRAINT cards_uid_name_key UNIQUE (uId, name);
+
+ALTER TABLE logins
+    ADD CONSTRAINT logins_uid_fkey FOREIGN KEY (uId) REFERENCES users (uId) ON DELETE CASCADE,
+    ADD CONSTRAINT logins_uid_name_key UNIQUE (uId, name);
+
+ALTER TABLE text_data
+    ADD CONSTRAINT text_data_uid_fkey FOREIGN KEY (uId) REFERENCES users (uId) ON DELETE CASCADE,
+    ADD CONSTRAINT text_data_uid_name_key UNIQUE (uId, name);
+
+ALTER TABLE binares_data
+    ADD CONSTRAINT binares_data_uid_fkey FOREIGN KEY (uId) REFERENCES users (uId) ON DELETE CASCADE,
+    ADD CONSTRAINT binares_data_uid_name_key UNIQUE (uId, name);
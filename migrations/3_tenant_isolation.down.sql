@@ -0,0 +1,15 @@
+DROP POLICY IF EXISTS binares_data_tenant ON binares_data;
+ALTER TABLE binares_data NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE binares_data DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS text_data_tenant ON text_data;
+ALTER TABLE text_data NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE text_data DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS logins_tenant ON logins;
+ALTER TABLE logins NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE logins DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS cards_tenant ON cards;
+ALTER TABLE cards NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE cards DISABLE ROW LEVEL SECURITY;
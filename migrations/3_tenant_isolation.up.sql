@@ -0,0 +1,38 @@
+-- Изоляция данных пользователей на уровне строк.
+-- Сервер выставляет app.user_id в каждой транзакции (set_config(..., true)),
+-- без него строки хранилища не видны и не могут быть записаны.
+-- Политики не действуют на суперпользователей и роли с BYPASSRLS, поэтому
+-- сервер должен подключаться под обычной ролью-владельцем или ролью с правами на таблицы.
+-- app.maintenance = 'on' используется служебными инструментами и миграциями данных.
+
+ALTER TABLE cards ENABLE ROW LEVEL SECURITY;
+ALTER TABLE cards FORCE ROW LEVEL SECURITY;
+CREATE POLICY cards_tenant ON cards
+    USING (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on')
+    WITH CHECK (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on');
+
+ALTER TABLE logins ENABLE ROW LEVEL SECURITY;
+ALTER TABLE logins FORCE ROW LEVEL SECURITY;
+CREATE POLICY logins_tenant ON logins
+    USING (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on')
+    WITH CHECK (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on');
+
+ALTER TABLE text_data ENABLE ROW LEVEL SECURITY;
+ALTER TABLE text_data FORCE ROW LEVEL SECURITY;
+CREATE POLICY text_data_tenant ON text_data
+    USING (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on')
+    WITH CHECK (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on');
+
+ALTER TABLE binares_data ENABLE ROW LEVEL SECURITY;
+ALTER TABLE binares_data FORCE ROW LEVEL SECURITY;
+CREATE POLICY binares_data_tenant ON binares_data
+    USING (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on')
+    WITH CHECK (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on');
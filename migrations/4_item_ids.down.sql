@@ -0,0 +1,57 @@
+-- Откат возвращает уникальность имени: из записей с одинаковым именем
+-- остается самая свежая, остальные переносятся в migration_quarantine
+-- с причиной name_conflict и возвращаются повторным применением миграции.
+-- Миграция данных выполняется в обход RLS.
+SET app.maintenance = 'on';
+
+DROP INDEX IF EXISTS cards_uid_name_idx;
+WITH moved AS (
+    DELETE FROM cards a USING cards b
+        WHERE a.uId = b.uId AND a.name = b.name AND (a.last_update, a.cId) < (b.last_update, b.cId)
+        RETURNING a.*)
+INSERT INTO migration_quarantine (source, reason, data)
+    SELECT 'cards', 'name_conflict', to_jsonb(moved) FROM moved;
+ALTER TABLE cards ADD CONSTRAINT cards_uid_name_key UNIQUE (uId, name);
+ALTER TABLE cards DROP COLUMN id;
+
+DROP INDEX IF EXISTS logins_uid_name_idx;
+WITH moved AS (
+    DELETE FROM logins a USING logins b
+        WHERE a.uId = b.uId AND a.name = b.name AND (a.last_update, a.lId) < (b.last_update, b.lId)
+        RETURNING a.*)
+INSERT INTO migration_quarantine (source, reason, data)
+    SELECT 'logins', 'name_conflict', to_jsonb(moved) FROM moved;
+ALTER TABLE logins ADD CONSTRAINT logins_uid_name_key UNIQUE (uId, name);
+ALTER TABLE logins DROP COLUMN id;
+
+DROP INDEX IF EXISTS text_data_uid_name_idx;
+WITH moved AS (
+    DELETE FROM text_data a USING text_data b
+        WHERE a.uId = b.uId AND a.name = b.name AND (a.last_update, a.tId) < (b.last_update, b.tId)
+        RETURNING a.*)
+INSERT INTO migration_quarantine (source, reason, data)
+    SELECT 'text_data', 'name_conflict', to_jsonb(moved) FROM moved;
+ALTER TABLE text_data ADD CONSTRAINT text_data_uid_name_key UNIQUE (uId, name);
+ALTER TABLE text_data DROP COLUMN id;
+
+DROP INDEX IF EXISTS binares_data_uid_name_idx;
+WITH moved AS (
+    DELETE FROM binares_data a USING binares_data b
+        WHERE a.uId = b.uId AND a.name = b.name AND (a.last_update, a.bId) < (b.last_update, b.bId)
+        RETURNING a.*)
+INSERT INTO migration_quarantine (source, reason, data)
+    SELECT 'binares_data', 'name_conflict', to_jsonb(moved) FROM moved;
+ALTER TABLE binares_data ADD CONSTRAINT binares_data_uid_name_key UNIQUE (uId, name);
+ALTER TABLE binares_data DROP COLUMN id;
+
+DO $$
+DECLARE
+    moved bigint;
+BEGIN
+    SELECT count(*) INTO moved FROM migration_quarantine WHERE reason = 'name_conflict';
+    IF moved > 0 THEN
+        RAISE WARNING '% rows with duplicate names were moved to migration_quarantine', moved;
+    END IF;
+END $$;
+
+RESET app.maintenance;
@@ -0,0 +1,40 @@
+-- Серверные идентификаторы записей. Существующие строки получают UUID,
+-- имя становится обычным изменяемым полем и больше не уникально.
+-- Записи, перенесенные в карантин откатом этой миграции, возвращаются
+-- с прежними id.
+
+ALTER TABLE cards ADD COLUMN id uuid NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE cards ADD CONSTRAINT cards_id_key UNIQUE (id);
+ALTER TABLE cards DROP CONSTRAINT cards_uid_name_key;
+CREATE INDEX cards_uid_name_idx ON cards (uId, name);
+
+ALTER TABLE logins ADD COLUMN id uuid NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE logins ADD CONSTRAINT logins_id_key UNIQUE (id);
+ALTER TABLE logins DROP CONSTRAINT logins_uid_name_key;
+CREATE INDEX logins_uid_name_idx ON logins (uId, name);
+
+ALTER TABLE text_data ADD COLUMN id uuid NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE text_data ADD CONSTRAINT text_data_id_key UNIQUE (id);
+ALTER TABLE text_data DROP CONSTRAINT text_data_uid_name_key;
+CREATE INDEX text_data_uid_name_idx ON text_data (uId, name);
+
+ALTER TABLE binares_data ADD COLUMN id uuid NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE binares_data ADD CONSTRAINT binares_data_id_key UNIQUE (id);
+ALTER TABLE binares_data DROP CONSTRAINT binares_data_uid_name_key;
+CREATE INDEX binares_data_uid_name_idx ON binares_data (uId, name);
+
+SET app.maintenance = 'on';
+INSERT INTO cards
+    SELECT (jsonb_populate_record(NULL::cards, data)).* FROM migration_quarantine
+    WHERE source = 'cards' AND reason = 'name_conflict';
+INSERT INTO logins
+    SELECT (jsonb_populate_record(NULL::logins, data)).* FROM migration_quarantine
+    WHERE source = 'logins' AND reason = 'name_conflict';
+INSERT INTO text_data
+    SELECT (jsonb_populate_record(NULL::text_data, data)).* FROM migration_quarantine
+    WHERE source = 'text_data' AND reason = 'name_conflict';
+INSERT INTO binares_data
+    SELECT (jsonb_populate_record(NULL::binares_data, data)).* FROM migration_quarantine
+    WHERE source = 'binares_data' AND reason = 'name_conflict';
+DELETE FROM migration_quarantine WHERE reason = 'name_conflict';
+RESET app.maintenance;
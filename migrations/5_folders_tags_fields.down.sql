@@ -0,0 +1,18 @@
+ALTER TABLE cards
+    DROP COLUMN IF EXISTS custom_fields,
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS folder_id;
+ALTER TABLE logins
+    DROP COLUMN IF EXISTS custom_fields,
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS folder_id;
+ALTER TABLE text_data
+    DROP COLUMN IF EXISTS custom_fields,
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS folder_id;
+ALTER TABLE binares_data
+    DROP COLUMN IF EXISTS custom_fields,
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS folder_id;
+
+DROP TABLE IF EXISTS folders;
@@ -0,0 +1,42 @@
+-- Папки, теги и произвольные поля записей.
+
+CREATE TABLE IF NOT EXISTS folders (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name varchar(100) NOT NULL,
+    uId integer NOT NULL REFERENCES users (uId) ON DELETE CASCADE,
+    deleted boolean NOT NULL,
+    last_update timestamp with time zone NOT NULL
+);
+CREATE INDEX folders_uid_idx ON folders (uId);
+
+ALTER TABLE folders ENABLE ROW LEVEL SECURITY;
+ALTER TABLE folders FORCE ROW LEVEL SECURITY;
+CREATE POLICY folders_tenant ON folders
+    USING (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on')
+    WITH CHECK (uId = NULLIF(current_setting('app.user_id', true), '')::integer
+        OR current_setting('app.maintenance', true) = 'on');
+
+ALTER TABLE cards
+    ADD COLUMN folder_id uuid REFERENCES folders (id) ON DELETE SET NULL,
+    ADD COLUMN tags text[] NOT NULL DEFAULT '{}',
+    ADD COLUMN custom_fields jsonb NOT NULL DEFAULT '[]';
+CREATE INDEX cards_tags_idx ON cards USING gin (tags);
+
+ALTER TABLE logins
+    ADD COLUMN folder_id uuid REFERENCES folders (id) ON DELETE SET NULL,
+    ADD COLUMN tags text[] NOT NULL DEFAULT '{}',
+    ADD COLUMN custom_fields jsonb NOT NULL DEFAULT '[]';
+CREATE INDEX logins_tags_idx ON logins USING gin (tags);
+
+ALTER TABLE text_data
+    ADD COLUMN folder_id uuid REFERENCES folders (id) ON DELETE SET NULL,
+    ADD COLUMN tags text[] NOT NULL DEFAULT '{}',
+    ADD COLUMN custom_fields jsonb NOT NULL DEFAULT '[]';
+CREATE INDEX text_data_tags_idx ON text_data USING gin (tags);
+
+ALTER TABLE binares_data
+    ADD COLUMN folder_id uuid REFERENCES folders (id) ON DELETE SET NULL,
+    ADD COLUMN tags text[] NOT NULL DEFAULT '{}',
+    ADD COLUMN custom_fields jsonb NOT NULL DEFAULT '[]';
+CREATE INDEX binares_data_tags_idx ON binares_data USING gin (tags);
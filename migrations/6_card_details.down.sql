@@ -0,0 +1,7 @@
+ALTER TABLE cards
+    DROP COLUMN IF EXISTS brand,
+    DROP COLUMN IF EXISTS pin,
+    DROP COLUMN IF EXISTS billing_address,
+    DROP COLUMN IF EXISTS cardholder,
+    ALTER COLUMN number TYPE varchar(16) USING left(number, 16),
+    ALTER COLUMN cvv TYPE integer USING COALESCE(NULLIF(cvv, ''), '0')::integer;
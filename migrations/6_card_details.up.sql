@@ -0,0 +1,11 @@
+-- CVV хранится строкой: ведущие нули терялись при хранении в integer.
+-- CVV состоит из 3-4 цифр, поэтому значения меньше 100 дополняются нулями.
+-- Номер карты - до 19 цифр. Новые поля: держатель, адрес, PIN и платежная система.
+
+ALTER TABLE cards
+    ALTER COLUMN cvv TYPE varchar(4) USING lpad(cvv::text, 3, '0'),
+    ALTER COLUMN number TYPE varchar(19),
+    ADD COLUMN cardholder varchar(100) NOT NULL DEFAULT '',
+    ADD COLUMN billing_address text NOT NULL DEFAULT '',
+    ADD COLUMN pin varchar(12) NOT NULL DEFAULT '',
+    ADD COLUMN brand varchar(20) NOT NULL DEFAULT '';
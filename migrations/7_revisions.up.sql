@@ -0,0 +1,3 @@
+-- Ревизия хранилища пользователя: увеличивается при каждой синхронизации
+-- или импорте, изменивших записи, и передается в событиях WatchChanges.
+ALTER TABLE users ADD COLUMN revision bigint NOT NULL DEFAULT 0;
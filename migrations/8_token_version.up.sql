@@ -0,0 +1,3 @@
+-- Версия токенов пользователя: токены с меньшей версией отзываются
+-- при смене пароля.
+ALTER TABLE users ADD COLUMN token_version integer NOT NULL DEFAULT 0;
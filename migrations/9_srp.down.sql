@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS srp_sessions;
+-- Учетные записи, уже переведенные на SRP, после отката не смогут войти
+-- по паролю: их пароль должен быть сброшен.
+UPDATE users SET hash = '' WHERE hash IS NULL;
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_credentials_check,
+    ALTER COLUMN hash SET NOT NULL,
+    DROP COLUMN IF EXISTS srp_verifier,
+    DROP COLUMN IF EXISTS srp_salt;
@@ -0,0 +1,22 @@
+-- Вход по протоколу SRP-6a: сервер хранит соль и верификатор вместо
+-- хеша пароля. Учетные записи с bcrypt-хешем переводятся на SRP при
+-- следующем входе с паролем, после чего hash очищается.
+ALTER TABLE users
+    ADD COLUMN srp_salt bytea,
+    ADD COLUMN srp_verifier bytea,
+    ALTER COLUMN hash DROP NOT NULL,
+    ADD CONSTRAINT users_credentials_check
+        CHECK (hash IS NOT NULL OR (srp_salt IS NOT NULL AND srp_verifier IS NOT NULL));
+
+-- Незавершенные входы: открытое число клиента A и числа сервера b и B
+-- между первым и вторым шагом. Строка удаляется при проверке
+-- доказательства клиента или по истечении expires_at.
+CREATE TABLE IF NOT EXISTS srp_sessions (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    uId integer NOT NULL REFERENCES users (uId) ON DELETE CASCADE,
+    client_public bytea NOT NULL,
+    server_secret bytea NOT NULL,
+    server_public bytea NOT NULL,
+    expires_at timestamp with time zone NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_srp_sessions_expires ON srp_sessions (expires_at);